@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/configuration"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configPrintCmd = &cobra.Command{
+		Use:   "config:print",
+		Short: "Print the effective configuration of the run command",
+		Long: `Print the settings the run command would use, after merging
+the config file, the environment and the flags and populating
+the defaults. The settings are validated before being printed.`,
+		RunE: runConfigPrintCmd,
+	}
+
+	configPrintFile  string
+	configPrintFlags = &configuration.Settings{}
+)
+
+func init() {
+	addSettingsFlags(configPrintCmd, &configPrintFile, configPrintFlags)
+}
+
+func runConfigPrintCmd(cmd *cobra.Command, args []string) error {
+	settings, err := configuration.LoadSettings(configPrintFile, configPrintFlags)
+	if err != nil {
+		return err
+	}
+
+	if _, err := configuration.NewConfiguration(settings); err != nil {
+		return fmt.Errorf("%w: invalid configuration", err)
+	}
+
+	fmt.Println(settings.String())
+	return nil
+}
+
+// addSettingsFlags adds the flags overriding the settings of
+// the config file and the environment to cmd.
+func addSettingsFlags(cmd *cobra.Command, configFile *string, settings *configuration.Settings) {
+	flags := cmd.Flags()
+	flags.StringVar(configFile, "config", "", "path of the TOML config file")
+	flags.StringVar(&settings.Mode, "mode", "", "ONLINE or OFFLINE")
+	flags.StringVar(&settings.Network, "network", "", "MAINNET, TESTNET, DEVNET or CUSTOM")
+	flags.IntVar(&settings.Port, "port", 0, "port of the Rosetta server")
+	flags.StringVar(&settings.Tomo, "tomo", "", "comma-separated URLs (http, ws or IPC) of running tomo nodes")
+	flags.StringVar(&settings.ChainSpec, "chain-spec", "", "chain-spec file of a CUSTOM network")
+	flags.StringVar(&settings.TipPolicy, "tip-policy", "", "LATEST, CONFIRMATIONS or DOUBLE_VALIDATED")
+	flags.Var(uint64Setting(&settings.TipConfirmations), "tip-confirmations", "confirmations of the tip for the CONFIRMATIONS tip policy")
+	flags.Var(uint64Setting(&settings.SyncStaleness), "sync-staleness", "age in seconds above which the latest block is stale, 0 disables the check (default max-head-age)")
+	flags.StringVar(&settings.ReadTimeout, "read-timeout", "", "maximum duration for reading a request")
+	flags.StringVar(&settings.WriteTimeout, "write-timeout", "", "maximum duration for writing a response")
+	flags.StringVar(&settings.IdleTimeout, "idle-timeout", "", "maximum duration to wait for the next request")
+	flags.StringVar(&settings.TomoTimeout, "tomo-timeout", "", "timeout of a request to tomo")
+	flags.StringVar(&settings.TracerTimeout, "tracer-timeout", "", "timeout of the call tracer for a transaction")
+	flags.StringVar(&settings.TracerFile, "tracer-file", "", "call tracer replacing the bundled one")
+	flags.StringVar(&settings.LogFormat, "log-format", "", "format of the logs: logfmt or json")
+	flags.StringVar(&settings.LogLevel, "log-level", "", "maximum level of the logs: trace, debug, info, warn, error or crit")
+	flags.StringVar(&settings.MaxHeadAge, "max-head-age", "", "age of the latest block of tomo above which /readyz fails")
+	flags.Var(int64Setting(&settings.MaxTraceConcurrency), "max-trace-concurrency", "maximum number of transactions traced at the same time")
+	flags.StringVar(&settings.TomoBinary, "tomo-binary", "", "path of the embedded tomo binary")
+	flags.Var(boolSetting(&settings.IncludeZeroValueCalls), "include-zero-value-calls", "add operations without amount for calls transferring no value")
+	flags.Lookup("include-zero-value-calls").NoOptDefVal = "true"
+	flags.StringVar(&settings.TLSCertFile, "tls-cert-file", "", "certificate file to serve TLS")
+	flags.StringVar(&settings.TLSKeyFile, "tls-key-file", "", "key file to serve TLS")
+	flags.StringVar(&settings.TLSClientCAFile, "tls-client-ca-file", "", "CA file to verify client certificates with")
+	flags.StringSliceVar(&settings.PublicGroups, "public-groups", nil, "endpoint groups open to clients without an api key")
+	flags.Var(float64Setting(&settings.RateLimit), "rate-limit", "requests per second allowed per api key or IP")
+	flags.IntVar(&settings.RateBurst, "rate-burst", 0, "burst of requests allowed per api key or IP")
+	flags.Var(intSetting(&settings.DataConcurrency), "data-concurrency", "data requests served at the same time")
+	flags.Var(intSetting(&settings.DataQueueSize), "data-queue-size", "data requests waiting to be served")
+	flags.Var(intSetting(&settings.ConstructionConcurrency), "construction-concurrency", "construction requests served at the same time")
+	flags.Var(intSetting(&settings.ConstructionQueueSize), "construction-queue-size", "construction requests waiting to be served")
+}
+
+// settingFlag is the flag of a setting which is a pointer, it populates
+// the setting only when the flag is set so that an explicit zero value
+// overrides the config file and the environment.
+type settingFlag struct {
+	value string
+	kind  string
+	set   func(value string) error
+}
+
+func (f *settingFlag) String() string {
+	return f.value
+}
+
+func (f *settingFlag) Set(value string) error {
+	if err := f.set(value); err != nil {
+		return err
+	}
+	f.value = value
+	return nil
+}
+
+func (f *settingFlag) Type() string {
+	return f.kind
+}
+
+func boolSetting(setting **bool) *settingFlag {
+	return &settingFlag{kind: "bool", set: func(value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*setting = &v
+		return nil
+	}}
+}
+
+func uint64Setting(setting **uint64) *settingFlag {
+	return &settingFlag{kind: "uint64", set: func(value string) error {
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*setting = &v
+		return nil
+	}}
+}
+
+func int64Setting(setting **int64) *settingFlag {
+	return &settingFlag{kind: "int64", set: func(value string) error {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*setting = &v
+		return nil
+	}}
+}
+
+func intSetting(setting **int) *settingFlag {
+	return &settingFlag{kind: "int", set: func(value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*setting = &v
+		return nil
+	}}
+}
+
+func float64Setting(setting **float64) *settingFlag {
+	return &settingFlag{kind: "float64", set: func(value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*setting = &v
+		return nil
+	}}
+}
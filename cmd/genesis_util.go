@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsGenesisHashCmd = &cobra.Command{
+		Use:   "utils:genesis-hash",
+		Short: "Print the genesis block hash of a genesis file",
+		Long: `The genesis block identifiers served by /network/status
+are computed from the genesis files bundled in tomochain/genesis_files.
+This command computes the genesis block hash given the path of a
+TomoChain genesis file.
+When calling this command, you must provide 1 argument:
+[1] the location of the genesis file`,
+		RunE: runUtilsGenesisHashCmd,
+		Args: cobra.ExactArgs(1),
+	}
+)
+
+func runUtilsGenesisHashCmd(cmd *cobra.Command, args []string) error {
+	genesis, err := tomochain.LoadGenesisFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(tomochain.GenesisBlockIdentifier(genesis).Hash)
+	return nil
+}
@@ -6,8 +6,8 @@ import (
 	"os/signal"
 	"syscall"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/tomochain/tomochain/log"
 )
 
 var (
@@ -30,6 +30,9 @@ func Execute() error {
 func init() {
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(utilsBootstrapCmd)
+	rootCmd.AddCommand(utilsGenesisHashCmd)
+	rootCmd.AddCommand(utilsCheckSpecialRewardsCmd)
+	rootCmd.AddCommand(configPrintCmd)
 }
 
 // handleSignals handles OS signals so we can ensure we close database
@@ -40,7 +43,7 @@ func handleSignals(listeners []context.CancelFunc) {
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		sig := <-sigs
-		color.Red("received signal", "signal", sig)
+		log.Warn("received signal", "signal", sig)
 		SignalReceived = true
 		for _, listener := range listeners {
 			listener()
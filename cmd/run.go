@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"github.com/tomochain/tomochain-rosetta-gateway/common"
-	"log"
 	"net/http"
 	"time"
 
@@ -16,38 +18,47 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+	"github.com/tomochain/tomochain/log"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	// readTimeout is the maximum duration for reading the entire
-	// request, including the body.
-	readTimeout = 5 * time.Second
-
-	// writeTimeout is the maximum duration before timing out
-	// writes of the response. It is reset whenever a new
-	// request's header is read.
-	writeTimeout = 120 * time.Second
-
-	// idleTimeout is the maximum amount of time to wait for the
-	// next request when keep-alives are enabled.
-	idleTimeout = 30 * time.Second
+	// chainIDRetryInterval is the time to wait before asking
+	// tomo for its chain ID again while it is not reachable.
+	chainIDRetryInterval = 5 * time.Second
 )
 
 var (
 	runCmd = &cobra.Command{
 		Use:   "run",
 		Short: "Run tomochain-rosetta",
-		RunE:  runRunCmd,
+		Long: `Run tomochain-rosetta.
+
+Settings are read from a TOML config file (--config or CONFIG_FILE),
+the environment (MODE, NETWORK, PORT, TOMO, ...) and the flags below.
+Flags take precedence over the environment, which takes precedence
+over the config file. Run config:print to show the effective settings.`,
+		RunE: runRunCmd,
 	}
+
+	runConfigFile string
+	runFlags      = &configuration.Settings{}
 )
 
+func init() {
+	addSettingsFlags(runCmd, &runConfigFile, runFlags)
+}
+
 func runRunCmd(cmd *cobra.Command, args []string) error {
-	cfg, err := configuration.LoadConfiguration()
+	cfg, err := configuration.LoadConfiguration(runConfigFile, runFlags)
 	if err != nil {
 		return fmt.Errorf("%w: unable to load configuration", err)
 	}
+	if err := common.SetupLogger(cfg.LogFormat, cfg.LogLevel); err != nil {
+		return err
+	}
 
 	// The asserter automatically rejects incorrectly formatted
 	// requests.
@@ -55,7 +66,7 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		common.OperationTypes,
 		common.HistoricalBalanceSupported,
 		[]*types.NetworkIdentifier{cfg.Network},
-		tomochain.CallMethods,
+		tomochain.DefaultCallRegistry.Names(),
 		false,
 	)
 	if err != nil {
@@ -69,36 +80,74 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	g, ctx := errgroup.WithContext(ctx)
 
+	tlsConfig, err := loadTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+
 	var client *tomochain.Client
 	if cfg.Mode == configuration.Online {
-		if !cfg.RemoteTomo {
-			g.Go(func() error {
-				return tomochain.StartTomo(ctx, cfg.TomoArguments, g)
-			})
-		}
+		// the client connects to tomo lazily, so that it
+		// can be created before the embedded tomo is started
+		client, err = tomochain.NewClient(cfg.TomoURLs, cfg.ChainRules, &tomochain.ClientOptions{
+			TipPolicy:           cfg.TipPolicy,
+			SyncStaleness:       cfg.SyncStaleness,
+			HTTPTimeout:         cfg.TomoTimeout,
+			MaxTraceConcurrency: cfg.MaxTraceConcurrency,
+			Tracer:              cfg.Tracer,
+			TracerTimeout:       cfg.TracerTimeout,
 
-		var err error
-		client, err = tomochain.NewClient(cfg.TomoURL, cfg.Params)
+			IncludeZeroValueCalls: cfg.IncludeZeroValueCalls,
+		})
 		if err != nil {
 			return fmt.Errorf("%w: cannot initialize tomochain client", err)
 		}
 		defer client.Close()
 	}
 
+	health := services.NewHealth(cfg, client)
+	if cfg.Mode == configuration.Online && !cfg.RemoteTomo {
+		g.Go(func() error {
+			return tomochain.StartTomo(ctx, cfg.TomoBinary, cfg.TomoArguments, cfg.GenesisFile, g)
+		})
+	}
+
 	router := services.NewBlockchainRouter(cfg, client, asserter)
 
-	loggedRouter := server.LoggerMiddleware(router)
-	corsRouter := server.CorsMiddleware(loggedRouter)
+	metadataRouter := services.BlockMetadataMiddleware(router)
+	startupRouter := health.StartupMiddleware(metadataRouter)
+	admissionRouter := services.AdmissionMiddleware(cfg, startupRouter)
+	limitedRouter := services.RateLimitMiddleware(cfg, admissionRouter)
+	authRouter := services.AuthMiddleware(cfg, limitedRouter)
+	recoveryRouter := services.RecoveryMiddleware(authRouter)
+	metricsRouter := services.MetricsMiddleware(recoveryRouter)
+	loggedRouter := services.LoggerMiddleware(metricsRouter)
+	requestIDRouter := services.RequestIDMiddleware(loggedRouter)
+	corsRouter := server.CorsMiddleware(requestIDRouter)
+
+	// metrics and probes are served without api key
+	// while the startup checks are running
+	mux := http.NewServeMux()
+	mux.Handle(services.MetricsPath, promhttp.Handler())
+	mux.Handle(services.LivenessPath, health.LivenessHandler())
+	mux.Handle(services.ReadinessPath, health.ReadinessHandler())
+	mux.Handle("/", corsRouter)
+
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      corsRouter,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
+		Handler:      mux,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		TLSConfig:    tlsConfig,
 	}
 
 	g.Go(func() error {
-		log.Printf("server listening on port %d", cfg.Port)
+		if tlsConfig != nil {
+			log.Info("server listening", "port", cfg.Port, "tls", true)
+			return server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		}
+		log.Info("server listening", "port", cfg.Port, "tls", false)
 		return server.ListenAndServe()
 	})
 
@@ -111,6 +160,29 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		return server.Shutdown(ctx)
 	})
 
+	if client != nil {
+		// Refuse to serve a network the node is not running
+		err = checkChainID(ctx, client, cfg.Network)
+		if err == nil {
+			err = checkGenesisBlock(ctx, client, cfg.GenesisBlockIdentifier)
+		}
+		if err == nil {
+			err = checkStoreReward(ctx, client)
+		}
+		if err == nil {
+			err = checkTracer(ctx, client)
+		}
+		if err != nil {
+			// stop tomo and report why it exited if it did
+			cancel()
+			if waitErr := g.Wait(); waitErr != nil && errors.Is(err, context.Canceled) {
+				return waitErr
+			}
+			return err
+		}
+	}
+	health.Started()
+
 	err = g.Wait()
 	if SignalReceived {
 		return errors.New("tomchain-rosetta halted")
@@ -118,3 +190,100 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	return err
 }
+
+// checkChainID waits until tomo reports its chain ID and
+// ensures it matches the configured network.
+func checkChainID(ctx context.Context, client *tomochain.Client, network *types.NetworkIdentifier) error {
+	for {
+		chainID, err := client.GetChainID(ctx)
+		if err == nil {
+			if chainID.String() != network.Network {
+				return fmt.Errorf(
+					"tomo chain ID %s does not match configured network %s",
+					chainID.String(),
+					network.Network,
+				)
+			}
+			return nil
+		}
+
+		log.Info("waiting for tomo chain ID", "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(chainIDRetryInterval):
+		}
+	}
+}
+
+// checkGenesisBlock ensures the genesis block of tomo
+// is the genesis block of the configured network.
+func checkGenesisBlock(ctx context.Context, client *tomochain.Client, genesis *types.BlockIdentifier) error {
+	block, err := client.GetGenesisBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get tomo genesis block", err)
+	}
+
+	if block.BlockIdentifier.Hash != genesis.Hash {
+		return fmt.Errorf(
+			"tomo genesis block %s does not match configured genesis block %s",
+			block.BlockIdentifier.Hash,
+			genesis.Hash,
+		)
+	}
+
+	return nil
+}
+
+// checkStoreReward ensures tomo stores the rewards of checkpoint
+// blocks, without which their reward transactions cannot be served.
+func checkStoreReward(ctx context.Context, client *tomochain.Client) error {
+	storesRewards, err := client.StoresRewards(ctx)
+	if err != nil {
+		log.Warn("unable to check tomo stores epoch rewards", "err", err)
+		return nil
+	}
+	if !storesRewards {
+		return errors.New("tomo does not store epoch rewards, start it with --store-reward")
+	}
+	return nil
+}
+
+// checkTracer ensures tomo runs the call tracer,
+// without which transactions cannot be parsed.
+func checkTracer(ctx context.Context, client *tomochain.Client) error {
+	err := client.CheckTracer(ctx)
+	if errors.Is(err, tomochain.ErrTracerRejected) {
+		return fmt.Errorf("%w, check tracer_file and that tomo serves the debug API", err)
+	}
+	if err != nil {
+		log.Warn("unable to check tomo runs the call tracer", "err", err)
+	}
+	return nil
+}
+
+// loadTLSConfig returns the TLS config of the server, or nil if TLS is
+// disabled. Client certificates are required when a client CA is set.
+func loadTLSConfig(cfg *configuration.Configuration) (*tls.Config, error) {
+	if len(cfg.TLSCertFile) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(cfg.TLSClientCAFile) > 0 {
+		caCert, err := ioutil.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read tls client CA file %s", err, cfg.TLSClientCAFile)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in tls client CA file %s", cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
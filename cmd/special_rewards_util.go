@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/configuration"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsCheckSpecialRewardsCmd = &cobra.Command{
+		Use:   "utils:check-special-rewards",
+		Short: "Check the special rewards of a network against tomo balances",
+		Long: `Special rewards are one-off balance changes of TomoChain
+which are not caused by a transaction or an epoch reward. They
+are bundled per network in tomochain/special_rewards, or listed
+in the chain-spec file of a CUSTOM network.
+This command ensures that, for each special reward of the configured
+network, the balance of its address changes in its block by the
+amounts of the operations served for the block. The tomo nodes
+must be running and keep the state of the rewarded blocks.`,
+		RunE: runUtilsCheckSpecialRewardsCmd,
+	}
+
+	checkSpecialRewardsConfigFile string
+	checkSpecialRewardsFlags      = &configuration.Settings{}
+)
+
+func init() {
+	addSettingsFlags(utilsCheckSpecialRewardsCmd, &checkSpecialRewardsConfigFile, checkSpecialRewardsFlags)
+}
+
+func runUtilsCheckSpecialRewardsCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := configuration.LoadConfiguration(checkSpecialRewardsConfigFile, checkSpecialRewardsFlags)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load configuration", err)
+	}
+
+	client, err := tomochain.NewClient(cfg.TomoURLs, cfg.ChainRules, &tomochain.ClientOptions{
+		HTTPTimeout:         cfg.TomoTimeout,
+		MaxTraceConcurrency: cfg.MaxTraceConcurrency,
+		Tracer:              cfg.Tracer,
+		TracerTimeout:       cfg.TracerTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize tomochain client", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	mismatches := 0
+	for _, reward := range cfg.ChainRules.SpecialRewards {
+		err := client.CheckSpecialReward(ctx, reward)
+		switch {
+		case errors.Is(err, tomochain.ErrSpecialRewardMismatch):
+			mismatches++
+			fmt.Printf("block %d %s: %v\n", reward.Block, reward.Address, err)
+		case err != nil:
+			return fmt.Errorf("%w: unable to check special reward of block %d", err, reward.Block)
+		default:
+			fmt.Printf("block %d %s: ok\n", reward.Block, reward.Address)
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf(
+			"%d of %d special rewards of network %s do not match tomo balances",
+			mismatches,
+			len(cfg.ChainRules.SpecialRewards),
+			cfg.Network.Network,
+		)
+	}
+	fmt.Printf("%d special rewards of network %s match tomo balances\n", len(cfg.ChainRules.SpecialRewards), cfg.Network.Network)
+	return nil
+}
@@ -234,6 +234,61 @@ var (
 		Message: "Call method invalid",
 	}
 
+	// ErrUnauthorized is returned when a request
+	// requires an api key and none or an unknown
+	// one is provided.
+	ErrUnauthorized = &types.Error{
+		Code:    37, //nolint
+		Message: "Unauthorized",
+	}
+
+	// ErrForbidden is returned when the api key of
+	// a request does not grant access to the endpoint.
+	ErrForbidden = &types.Error{
+		Code:    38, //nolint
+		Message: "Forbidden",
+	}
+
+	// ErrRateLimited is returned when a client
+	// exceeds its request rate.
+	ErrRateLimited = &types.Error{
+		Code:      39, //nolint
+		Message:   "Rate limit exceeded",
+		Retriable: true,
+	}
+
+	// ErrServerBusy is returned when too many requests
+	// to the same endpoint group are being served.
+	ErrServerBusy = &types.Error{
+		Code:      40, //nolint
+		Message:   "Server busy",
+		Retriable: true,
+	}
+
+	// ErrNotReady is returned while the startup checks
+	// of tomochain-rosetta are running.
+	ErrNotReady = &types.Error{
+		Code:      41, //nolint
+		Message:   "Server is starting",
+		Retriable: true,
+	}
+
+	// ErrRewardsUnavailable is returned when tomo has not stored the
+	// rewards of a checkpoint block, it must run with --store-reward.
+	ErrRewardsUnavailable = &types.Error{
+		Code:      42, //nolint
+		Message:   "Epoch rewards unavailable, tomo must run with --store-reward",
+		Retriable: true,
+	}
+
+	// ErrTransactionNotFound is returned by /block/transaction
+	// when the block has no transaction with the identifier.
+	ErrTransactionNotFound = &types.Error{
+		Code:      43, //nolint
+		Message:   "Transaction not found in block",
+		Retriable: false,
+	}
+
 	ErrorList = []*types.Error{
 		ErrUnableToGetChainID,
 		ErrInvalidBlockchain,
@@ -271,5 +326,24 @@ var (
 		ErrCallOutputMarshal,
 		ErrCallMethodInvalid,
 		ErrCallParametersInvalid,
+		ErrUnauthorized,
+		ErrForbidden,
+		ErrRateLimited,
+		ErrServerBusy,
+		ErrNotReady,
+		ErrRewardsUnavailable,
+		ErrTransactionNotFound,
 	}
 )
+
+// WrapErr adds the message of err to the details of a Rosetta error.
+func WrapErr(rErr *types.Error, err error) *types.Error {
+	return &types.Error{
+		Code:      rErr.Code,
+		Message:   rErr.Message,
+		Retriable: rErr.Retriable,
+		Details: map[string]interface{}{
+			"context": err.Error(),
+		},
+	}
+}
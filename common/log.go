@@ -0,0 +1,76 @@
+// Copyright (c) 2020 TomoChain
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"github.com/tomochain/tomochain/log"
+	"os"
+)
+
+const (
+	// LOG_FORMAT_LOGFMT writes a record per line as key=value pairs.
+	LOG_FORMAT_LOGFMT = "logfmt"
+
+	// LOG_FORMAT_JSON writes a record per line as a JSON object.
+	LOG_FORMAT_JSON = "json"
+
+	// LOG_FIELD_REQUEST_ID is the field carrying the ID of the request
+	// a record was written for.
+	LOG_FIELD_REQUEST_ID = "request_id"
+)
+
+type requestIDKey struct{}
+
+func init() {
+	// the root logger of tomochain discards records until it is set up
+	log.Root().SetHandler(logHandler(log.LogfmtFormat(), log.LvlInfo))
+}
+
+// SetupLogger sets the format and the maximum level of the records
+// written by tomochain-rosetta and by the tomochain packages it uses.
+func SetupLogger(format string, level log.Lvl) error {
+	var fmtr log.Format
+	switch format {
+	case LOG_FORMAT_LOGFMT:
+		fmtr = log.LogfmtFormat()
+	case LOG_FORMAT_JSON:
+		fmtr = log.JsonFormat()
+	default:
+		return fmt.Errorf("%s is not a valid log format", format)
+	}
+
+	log.Root().SetHandler(logHandler(fmtr, level))
+	return nil
+}
+
+func logHandler(fmtr log.Format, level log.Lvl) log.Handler {
+	return log.LvlFilterHandler(level, log.StreamHandler(os.Stderr, fmtr))
+}
+
+// WithRequestID returns a copy of ctx carrying the ID of a request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID of the request carried by ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Logger returns the logger of the request carried by ctx,
+// its records are tagged with the ID of the request.
+func Logger(ctx context.Context) log.Logger {
+	if id := RequestID(ctx); len(id) > 0 {
+		return log.New(LOG_FIELD_REQUEST_ID, id)
+	}
+	return log.Root()
+}
+
+// RedactPayload hides a signed payload, such as a signed
+// transaction, so that it can be written to the logs.
+func RedactPayload(payload string) string {
+	return fmt.Sprintf("<redacted %d chars>", len(payload))
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2020 TomoChain
+
+package configuration
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// DataGroup is the group of the read-only data endpoints.
+	DataGroup = "data"
+
+	// ConstructionGroup is the group of the construction
+	// endpoints, except /construction/submit.
+	ConstructionGroup = "construction"
+
+	// SubmitGroup is the group of /construction/submit.
+	SubmitGroup = "submit"
+)
+
+// EndpointGroups are the groups of endpoints access is granted to.
+var EndpointGroups = []string{DataGroup, ConstructionGroup, SubmitGroup}
+
+// APIKey grants a client access to groups of endpoints. The key
+// is sent in the X-API-Key header or as a bearer token.
+type APIKey struct {
+	Name   string   `toml:"name"`
+	Key    string   `toml:"key"`
+	Groups []string `toml:"groups"`
+}
+
+// validateAPIKeys ensures the API keys are unique and only
+// grant access to known groups.
+func validateAPIKeys(keys []*APIKey, publicGroups []string) error {
+	if err := validateGroups(publicGroups); err != nil {
+		return fmt.Errorf("%w: invalid public_groups", err)
+	}
+
+	names := map[string]bool{}
+	values := map[string]bool{}
+	for _, key := range keys {
+		if len(key.Name) == 0 {
+			return errors.New("api key name must be populated")
+		}
+		if len(key.Key) == 0 {
+			return fmt.Errorf("api key %s must be populated", key.Name)
+		}
+		if names[key.Name] {
+			return fmt.Errorf("api key %s is defined twice", key.Name)
+		}
+		if values[key.Key] {
+			return fmt.Errorf("api key %s reuses the key of another api key", key.Name)
+		}
+		names[key.Name] = true
+		values[key.Key] = true
+		if err := validateGroups(key.Groups); err != nil {
+			return fmt.Errorf("%w: invalid groups of api key %s", err, key.Name)
+		}
+	}
+
+	return nil
+}
+
+func validateGroups(groups []string) error {
+	for _, group := range groups {
+		switch group {
+		case DataGroup, ConstructionGroup, SubmitGroup:
+		default:
+			return fmt.Errorf("%s is not a valid endpoint group", group)
+		}
+	}
+	return nil
+}
+
+// redactAPIKeys returns a copy of the API keys without the keys.
+func redactAPIKeys(keys []*APIKey) []*APIKey {
+	redacted := make([]*APIKey, len(keys))
+	for i, key := range keys {
+		redacted[i] = &APIKey{
+			Name:   key.Name,
+			Key:    "<redacted>",
+			Groups: key.Groups,
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2020 TomoChain
+
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strconv"
+)
+
+// ChainSpec describes a private TomoChain network
+// served when NETWORK is CUSTOM.
+type ChainSpec struct {
+	// ChainID is the chain ID of the network, it defaults
+	// to the chain ID of the genesis file.
+	ChainID uint64 `json:"chain_id"`
+
+	// Genesis is the path of the genesis file, relative
+	// to the chain-spec file.
+	Genesis string `json:"genesis"`
+
+	// Epoch is the number of blocks in an epoch, it defaults
+	// to the posv epoch of the genesis file.
+	Epoch uint64 `json:"epoch"`
+
+	// FeeHardForkBlock is the block from which transaction fees
+	// are paid to masternode owners, it defaults to the mainnet block.
+	FeeHardForkBlock *uint64 `json:"fee_hard_fork_block"`
+
+	// SpecialRewards are the one-off rewards of the network.
+	SpecialRewards []*tomochain.SpecialReward `json:"special_rewards"`
+
+	// TomoArguments are the arguments to start the embedded tomo.
+	TomoArguments string `json:"tomo_arguments"`
+
+	dir string
+}
+
+// LoadChainSpec reads a chain-spec file.
+func LoadChainSpec(specFile string) (*ChainSpec, error) {
+	data, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read chain-spec file %s", err, specFile)
+	}
+
+	spec := &ChainSpec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse chain-spec file %s", err, specFile)
+	}
+	spec.dir = filepath.Dir(specFile)
+
+	return spec, nil
+}
+
+// apply populates the network of config from the chain spec.
+func (s *ChainSpec) apply(config *Configuration) error {
+	if len(s.Genesis) == 0 {
+		return fmt.Errorf("genesis must be populated")
+	}
+	genesisFile := s.Genesis
+	if !filepath.IsAbs(genesisFile) {
+		genesisFile = filepath.Join(s.dir, genesisFile)
+	}
+	genesis, err := tomochain.LoadGenesisFile(genesisFile)
+	if err != nil {
+		return err
+	}
+	if genesis.Config == nil || genesis.Config.Posv == nil {
+		return fmt.Errorf("genesis file %s has no posv chain config", genesisFile)
+	}
+
+	// copy the chain config so the spec never alters the genesis
+	chainConfig := *genesis.Config
+	posvConfig := *genesis.Config.Posv
+	chainConfig.Posv = &posvConfig
+
+	if s.ChainID != 0 {
+		if chainConfig.ChainId != nil && chainConfig.ChainId.Uint64() != s.ChainID {
+			return fmt.Errorf(
+				"chain ID %d does not match chain ID %s of genesis file",
+				s.ChainID,
+				chainConfig.ChainId.String(),
+			)
+		}
+		chainConfig.ChainId = new(big.Int).SetUint64(s.ChainID)
+	}
+	if chainConfig.ChainId == nil {
+		return fmt.Errorf("chain ID must be populated")
+	}
+	if s.Epoch != 0 {
+		chainConfig.Posv.Epoch = s.Epoch
+	}
+
+	feeHardForkBlock := common.HardForkUpdateTxFee
+	if s.FeeHardForkBlock != nil {
+		feeHardForkBlock = new(big.Int).SetUint64(*s.FeeHardForkBlock)
+	}
+
+	config.Network = &types.NetworkIdentifier{
+		Blockchain: tomochain.Blockchain,
+		Network:    strconv.FormatUint(chainConfig.ChainId.Uint64(), 10),
+	}
+	config.GenesisBlockIdentifier = tomochain.GenesisBlockIdentifier(genesis)
+	config.GenesisFile = genesisFile
+	config.ChainRules = &tomochain.ChainRules{
+		Params:           &chainConfig,
+		FeeHardForkBlock: feeHardForkBlock,
+		SpecialRewards:   s.SpecialRewards,
+	}
+	config.TomoArguments = s.TomoArguments
+
+	return nil
+}
@@ -7,11 +7,13 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/pkg/errors"
 	"github.com/spf13/cast"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
 	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+	"github.com/tomochain/tomochain/log"
 	"github.com/tomochain/tomochain/params"
 	"math/big"
-	"os"
-	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -37,14 +39,15 @@ const (
 	PortEnv = "PORT"
 
 	// TomoEnv is an optional environment variable
-	// used to connect tomochain-rosetta to an already
-	// running tomo node.
+	// used to connect tomochain-rosetta to already
+	// running tomo nodes, given as comma-separated URLs.
 	TomoEnv = "TOMO"
 
 	// DefaultTomoURL is the default URL for
 	// a running geth node. This is used
-	// when GethEnv is not populated.
-	DefaultTomoURL = "http://localhost:8545"
+	// when GethEnv is not populated. The embedded
+	// tomo is reached through its IPC endpoint.
+	DefaultTomoURL = tomochain.TomoIPCPath
 
 	// Mainnet is the TomoChain Mainnet.
 	Mainnet string = "MAINNET"
@@ -54,6 +57,33 @@ const (
 
 	// Devnet is TomoChain network for development
 	Devnet string = "DEVNET"
+
+	// Custom is a private TomoChain network
+	// described by a chain-spec file.
+	Custom string = "CUSTOM"
+
+	// ChainSpecEnv is the environment variable read to
+	// determine the chain-spec file of a Custom network.
+	ChainSpecEnv = "CHAIN_SPEC"
+
+	// TipPolicyEnv is an optional environment variable
+	// read to determine which block is reported as the
+	// tip of the chain (LATEST, CONFIRMATIONS or DOUBLE_VALIDATED).
+	TipPolicyEnv = "TIP_POLICY"
+
+	// TipConfirmationsEnv is the environment variable read
+	// to determine the number of confirmations of the tip
+	// when TIP_POLICY is CONFIRMATIONS.
+	TipConfirmationsEnv = "TIP_CONFIRMATIONS"
+
+	// SyncStalenessEnv is an optional environment variable
+	// read to determine the age in seconds of the latest block
+	// above which the node is reported as not synced.
+	SyncStalenessEnv = "SYNC_STALENESS"
+
+	// DefaultGenesisFile is the genesis file used to
+	// initialize the data directory of the embedded tomo.
+	DefaultGenesisFile = "/app/genesis.json"
 )
 
 var (
@@ -68,20 +98,77 @@ type Configuration struct {
 	Mode                   Mode
 	Network                *types.NetworkIdentifier
 	GenesisBlockIdentifier *types.BlockIdentifier
-	TomoURL                string
+	TomoURLs               []string
 	RemoteTomo             bool
 	Port                   int
 	TomoArguments          string
+	GenesisFile            string
+
+	ChainRules *tomochain.ChainRules
+	TipPolicy  *tomochain.TipPolicy
+
+	// SyncStaleness is the age of the latest block above which
+	// the node is reported as not synced, zero disables the check. It
+	// defaults to MaxHeadAge, above which /readyz fails.
+	SyncStaleness time.Duration
 
-	Params *params.ChainConfig
+	ReadTimeout         time.Duration
+	WriteTimeout        time.Duration
+	IdleTimeout         time.Duration
+	TomoTimeout         time.Duration
+	TracerTimeout       time.Duration
+	Tracer              *tomochain.Tracer
+	MaxTraceConcurrency int64
+	TomoBinary          string
+
+	// IncludeZeroValueCalls adds operations without amount
+	// for the calls which transfer no value.
+	IncludeZeroValueCalls bool
+
+	// MaxHeadAge is the age of the latest block of tomo
+	// above which tomochain-rosetta is not ready.
+	MaxHeadAge time.Duration
+
+	LogFormat string
+	LogLevel  log.Lvl
+
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+
+	// APIKeys are the keys of the clients allowed to access the
+	// endpoint groups which are not in PublicGroups.
+	APIKeys      []*APIKey
+	PublicGroups []string
+
+	// RateLimit is the number of requests per second allowed
+	// per client, zero disables rate limiting.
+	RateLimit float64
+	RateBurst int
+
+	DataConcurrency         int
+	DataQueueSize           int
+	ConstructionConcurrency int
+	ConstructionQueueSize   int
 }
 
-// LoadConfiguration attempts to create a new Configuration
-// using the ENVs in the environment.
-func LoadConfiguration() (*Configuration, error) {
+// LoadConfiguration attempts to create a new Configuration using the
+// config file, the ENVs in the environment and the flags, see LoadSettings.
+func LoadConfiguration(configFile string, flags *Settings) (*Configuration, error) {
+	settings, err := LoadSettings(configFile, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConfiguration(settings)
+}
+
+// NewConfiguration validates the settings and creates
+// a new Configuration from them.
+func NewConfiguration(settings *Settings) (*Configuration, error) {
 	config := &Configuration{}
 
-	modeValue := Mode(os.Getenv(ModeEnv))
+	modeValue := Mode(settings.Mode)
 	switch modeValue {
 	case Online:
 		config.Mode = Online
@@ -93,7 +180,8 @@ func LoadConfiguration() (*Configuration, error) {
 		return nil, fmt.Errorf("%s is not a valid mode", modeValue)
 	}
 
-	networkValue := os.Getenv(NetworkEnv)
+	config.GenesisFile = DefaultGenesisFile
+	networkValue := settings.Network
 	switch networkValue {
 	case Mainnet:
 		config.Network = &types.NetworkIdentifier{
@@ -101,57 +189,206 @@ func LoadConfiguration() (*Configuration, error) {
 			Network:    tomochain.MainnetNetwork,
 		}
 		config.GenesisBlockIdentifier = tomochain.MainnetGenesisBlockIdentifier
-		config.Params = params.TomoMainnetChainConfig
+		config.ChainRules = &tomochain.ChainRules{
+			Params:           params.TomoMainnetChainConfig,
+			FeeHardForkBlock: common.HardForkUpdateTxFee,
+		}
 		config.TomoArguments = tomochain.MainnetTomoArguments
 	case Testnet:
 		config.Network = &types.NetworkIdentifier{
 			Blockchain: tomochain.Blockchain,
 			Network:    tomochain.TestnetNetwork,
 		}
-		config.GenesisBlockIdentifier = &types.BlockIdentifier{
-			Hash: "",
-			Index: tomochain.GenesisBlockIndex,
+		config.GenesisBlockIdentifier = tomochain.TestnetGenesisBlockIdentifier
+		config.ChainRules = &tomochain.ChainRules{
+			Params:           networkChainConfig(tomochain.TestnetNetwork),
+			FeeHardForkBlock: common.HardForkUpdateTxFee,
 		}
-		testnetChainConfig := params.TomoMainnetChainConfig
-		testnetChainConfig.ChainId = new(big.Int).SetUint64(cast.ToUint64(tomochain.TestnetNetwork))
-		config.Params = testnetChainConfig
 		config.TomoArguments = tomochain.TestnetTomoArguments
 	case Devnet:
 		config.Network = &types.NetworkIdentifier{
 			Blockchain: tomochain.Blockchain,
 			Network:    tomochain.DevnetNetwork,
 		}
-		config.GenesisBlockIdentifier = &types.BlockIdentifier{
-			Hash: "",
-			Index: tomochain.GenesisBlockIndex,
+		config.GenesisBlockIdentifier = tomochain.DevnetGenesisBlockIdentifier
+		config.ChainRules = &tomochain.ChainRules{
+			Params:           networkChainConfig(tomochain.DevnetNetwork),
+			FeeHardForkBlock: common.HardForkUpdateTxFee,
 		}
-		devnetChainConfig := params.TomoMainnetChainConfig
-		devnetChainConfig.ChainId = new(big.Int).SetUint64(cast.ToUint64(tomochain.DevnetNetwork))
-		config.Params = devnetChainConfig
 		config.TomoArguments = tomochain.DevnetTomoArguments
+	case Custom:
+		specFile := settings.ChainSpec
+		if len(specFile) == 0 {
+			return nil, fmt.Errorf("%s must be populated for network %s", ChainSpecEnv, Custom)
+		}
+		spec, err := LoadChainSpec(specFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := spec.apply(config); err != nil {
+			return nil, fmt.Errorf("%w: invalid chain-spec file %s", err, specFile)
+		}
 	case "":
 		return nil, errors.New("NETWORK must be populated")
 	default:
 		return nil, fmt.Errorf("%s is not a valid network", networkValue)
 	}
 
-	config.TomoURL = DefaultTomoURL
-	envGethURL := os.Getenv(TomoEnv)
-	if len(envGethURL) > 0 {
+	if networkValue != Custom {
+		// a chain spec lists the special rewards of its network
+		specialRewards, err := tomochain.SpecialRewardsOf(config.ChainRules.Params.ChainId)
+		if err != nil {
+			return nil, err
+		}
+		config.ChainRules.SpecialRewards = specialRewards
+	}
+
+	if err := config.ChainRules.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: invalid chain rules for network %s", err, networkValue)
+	}
+
+	config.TomoURLs = []string{DefaultTomoURL}
+	if len(settings.Tomo) > 0 {
 		config.RemoteTomo = true
-		config.TomoURL = envGethURL
+		config.TomoURLs = nil
+		for _, url := range strings.Split(settings.Tomo, ",") {
+			if url = strings.TrimSpace(url); len(url) > 0 {
+				config.TomoURLs = append(config.TomoURLs, url)
+			}
+		}
+		if len(config.TomoURLs) == 0 {
+			return nil, fmt.Errorf("%s is not a valid list of tomo URLs", settings.Tomo)
+		}
+	}
+
+	if config.Mode == Online && !config.RemoteTomo && len(config.TomoArguments) == 0 {
+		return nil, fmt.Errorf("tomo arguments must be populated to start tomo on network %s", networkValue)
 	}
+	config.TomoBinary = settings.TomoBinary
 
-	portValue := os.Getenv(PortEnv)
-	if len(portValue) == 0 {
+	config.TipPolicy = &tomochain.TipPolicy{Mode: settings.TipPolicy}
+	if settings.TipConfirmations != nil {
+		config.TipPolicy.Confirmations = *settings.TipConfirmations
+	}
+	if err := config.TipPolicy.Validate(); err != nil {
+		return nil, err
+	}
+	if settings.Port == 0 {
 		return nil, errors.New("PORT must be populated")
 	}
+	if settings.Port < 0 {
+		return nil, fmt.Errorf("%d is not a valid port", settings.Port)
+	}
+	config.Port = settings.Port
+
+	timeouts := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"read_timeout", settings.ReadTimeout, &config.ReadTimeout},
+		{"write_timeout", settings.WriteTimeout, &config.WriteTimeout},
+		{"idle_timeout", settings.IdleTimeout, &config.IdleTimeout},
+		{"tomo_timeout", settings.TomoTimeout, &config.TomoTimeout},
+		{"tracer_timeout", settings.TracerTimeout, &config.TracerTimeout},
+		{"max_head_age", settings.MaxHeadAge, &config.MaxHeadAge},
+	}
+	for _, timeout := range timeouts {
+		duration, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, timeout.name, timeout.value)
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("%s must be positive", timeout.name)
+		}
+		*timeout.dest = duration
+	}
+	// the node is synced as long as it is ready,
+	// an explicit zero disables the check
+	config.SyncStaleness = config.MaxHeadAge
+	if settings.SyncStaleness != nil {
+		config.SyncStaleness = time.Duration(*settings.SyncStaleness) * time.Second
+	}
+
+	switch settings.LogFormat {
+	case common.LOG_FORMAT_LOGFMT, common.LOG_FORMAT_JSON:
+	default:
+		return nil, fmt.Errorf("%s is not a valid log_format", settings.LogFormat)
+	}
+	config.LogFormat = settings.LogFormat
+	level, err := log.LvlFromString(settings.LogLevel)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid log_level", err)
+	}
+	config.LogLevel = level
+
+	tracer, err := tomochain.LoadTracer(settings.TracerFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tracer_file", err)
+	}
+	config.Tracer = tracer
 
-	port, err := strconv.Atoi(portValue)
-	if err != nil || len(portValue) == 0 || port <= 0 {
-		return nil, fmt.Errorf("%w: unable to parse port %s", err, portValue)
+	if settings.MaxTraceConcurrency == nil || *settings.MaxTraceConcurrency <= 0 {
+		return nil, fmt.Errorf("max_trace_concurrency must be positive")
+	}
+	config.MaxTraceConcurrency = *settings.MaxTraceConcurrency
+	config.IncludeZeroValueCalls = settings.IncludeZeroValueCalls != nil && *settings.IncludeZeroValueCalls
+
+	if (len(settings.TLSCertFile) == 0) != (len(settings.TLSKeyFile) == 0) {
+		return nil, errors.New("tls_cert_file and tls_key_file must be populated together")
+	}
+	if len(settings.TLSClientCAFile) > 0 && len(settings.TLSCertFile) == 0 {
+		return nil, errors.New("tls_client_ca_file requires tls_cert_file and tls_key_file")
+	}
+	config.TLSCertFile = settings.TLSCertFile
+	config.TLSKeyFile = settings.TLSKeyFile
+	config.TLSClientCAFile = settings.TLSClientCAFile
+
+	if err := validateAPIKeys(settings.APIKeys, settings.PublicGroups); err != nil {
+		return nil, err
+	}
+	config.APIKeys = settings.APIKeys
+	config.PublicGroups = settings.PublicGroups
+	if len(config.APIKeys) == 0 {
+		// authentication is disabled
+		config.PublicGroups = EndpointGroups
+	}
+
+	if settings.RateLimit != nil {
+		config.RateLimit = *settings.RateLimit
+	}
+	if config.RateLimit < 0 || settings.RateBurst < 0 {
+		return nil, errors.New("rate_limit and rate_burst must not be negative")
+	}
+	config.RateBurst = settings.RateBurst
+
+	// a queue size of zero rejects the requests
+	// when all of the pool is busy
+	pools := []struct {
+		name  string
+		value *int
+		min   int
+		dest  *int
+	}{
+		{"data_concurrency", settings.DataConcurrency, 1, &config.DataConcurrency},
+		{"data_queue_size", settings.DataQueueSize, 0, &config.DataQueueSize},
+		{"construction_concurrency", settings.ConstructionConcurrency, 1, &config.ConstructionConcurrency},
+		{"construction_queue_size", settings.ConstructionQueueSize, 0, &config.ConstructionQueueSize},
+	}
+	for _, pool := range pools {
+		if pool.value == nil || *pool.value < pool.min {
+			return nil, fmt.Errorf("%s must be at least %d", pool.name, pool.min)
+		}
+		*pool.dest = *pool.value
 	}
-	config.Port = port
 
 	return config, nil
 }
+
+// networkChainConfig returns a copy of the mainnet chain
+// config with the chain ID of the given network.
+func networkChainConfig(network string) *params.ChainConfig {
+	chainConfig := *params.TomoMainnetChainConfig
+	chainConfig.ChainId = new(big.Int).SetUint64(cast.ToUint64(network))
+	return &chainConfig
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2020 TomoChain
+
+package configuration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewConfigurationSyncStaleness(t *testing.T) {
+	tests := []struct {
+		name       string
+		staleness  *uint64
+		maxHeadAge string
+		want       time.Duration
+	}{
+		{name: "default", want: time.Minute},
+		{name: "max head age", maxHeadAge: "30s", want: 30 * time.Second},
+		{name: "override", staleness: uint64Ptr(10), want: 10 * time.Second},
+		{name: "disabled", staleness: uint64Ptr(0), want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := &Settings{
+				Mode:          string(Offline),
+				Network:       Mainnet,
+				Port:          8080,
+				SyncStaleness: test.staleness,
+				MaxHeadAge:    test.maxHeadAge,
+			}
+			settings.populateDefaults()
+
+			config, err := NewConfiguration(settings)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.SyncStaleness != test.want {
+				t.Fatalf("sync staleness %s, want %s", config.SyncStaleness, test.want)
+			}
+		})
+	}
+}
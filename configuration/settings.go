@@ -0,0 +1,383 @@
+// Copyright (c) 2020 TomoChain
+
+package configuration
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/naoina/toml"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+)
+
+const (
+	// ConfigFileEnv is an optional environment variable
+	// read to determine the config file.
+	ConfigFileEnv = "CONFIG_FILE"
+
+	// DefaultReadTimeout is the default maximum duration
+	// for reading the entire request, including the body.
+	DefaultReadTimeout = "5s"
+
+	// DefaultWriteTimeout is the default maximum duration before
+	// timing out writes of the response.
+	DefaultWriteTimeout = "120s"
+
+	// DefaultIdleTimeout is the default maximum amount of time to wait
+	// for the next request when keep-alives are enabled.
+	DefaultIdleTimeout = "30s"
+
+	// DefaultTomoTimeout is the default timeout of a request to tomo.
+	DefaultTomoTimeout = "120s"
+
+	// DefaultTracerTimeout is the default timeout of the call tracer
+	// for a single transaction.
+	DefaultTracerTimeout = "120s"
+
+	// DefaultMaxHeadAge is the default age of the latest block of
+	// tomo above which tomochain-rosetta is not ready.
+	DefaultMaxHeadAge = "60s"
+
+	// DefaultLogFormat is the default format of the logs.
+	DefaultLogFormat = common.LOG_FORMAT_LOGFMT
+
+	// DefaultLogLevel is the default maximum level of the logs.
+	DefaultLogLevel = "info"
+
+	// DefaultMaxTraceConcurrency is the default maximum number of
+	// transactions traced by tomo at the same time.
+	DefaultMaxTraceConcurrency = 16
+
+	// DefaultTomoBinary is the default path of the embedded tomo.
+	DefaultTomoBinary = "/app/tomo"
+
+	// DefaultDataConcurrency is the default number of requests
+	// to the data endpoints served at the same time.
+	DefaultDataConcurrency = 32
+
+	// DefaultConstructionConcurrency is the default number of requests
+	// to the construction endpoints served at the same time.
+	DefaultConstructionConcurrency = 16
+
+	// DefaultQueueSize is the default number of requests waiting
+	// to be served per endpoint pool, further requests are rejected.
+	DefaultQueueSize = 64
+)
+
+// Settings are the raw settings of tomochain-rosetta. They are read
+// from a TOML config file, the environment and the flags of the run
+// command. Flags take precedence over the environment, which takes
+// precedence over the config file. A zero value is an unset setting,
+// but for the pointers which are unset when nil.
+type Settings struct {
+	Mode             string  `toml:"mode,omitempty"`
+	Network          string  `toml:"network,omitempty"`
+	Port             int     `toml:"port,omitempty"`
+	Tomo             string  `toml:"tomo,omitempty"`
+	ChainSpec        string  `toml:"chain_spec,omitempty"`
+	TipPolicy        string  `toml:"tip_policy,omitempty"`
+	TipConfirmations *uint64 `toml:"tip_confirmations,omitempty"`
+
+	// SyncStaleness is in seconds, it defaults to
+	// max_head_age and zero disables it.
+	SyncStaleness *uint64 `toml:"sync_staleness,omitempty"`
+
+	// Timeouts are durations such as "30s" or "2m".
+	ReadTimeout   string `toml:"read_timeout,omitempty"`
+	WriteTimeout  string `toml:"write_timeout,omitempty"`
+	IdleTimeout   string `toml:"idle_timeout,omitempty"`
+	TomoTimeout   string `toml:"tomo_timeout,omitempty"`
+	TracerTimeout string `toml:"tracer_timeout,omitempty"`
+
+	// TracerFile is the path of a call tracer replacing the bundled one.
+	TracerFile string `toml:"tracer_file,omitempty"`
+
+	// MaxHeadAge is the age of the latest block of tomo
+	// above which /readyz fails, such as "60s".
+	MaxHeadAge string `toml:"max_head_age,omitempty"`
+
+	// LogFormat is "logfmt" or "json", LogLevel is one of "trace",
+	// "debug", "info", "warn", "error" and "crit".
+	LogFormat string `toml:"log_format,omitempty"`
+	LogLevel  string `toml:"log_level,omitempty"`
+
+	MaxTraceConcurrency *int64 `toml:"max_trace_concurrency,omitempty"`
+	TomoBinary          string `toml:"tomo_binary,omitempty"`
+
+	// IncludeZeroValueCalls adds operations without amount for the calls
+	// which transfer no value, a request can override it with the
+	// "include_zero_value_calls" metadata. It is a pointer so that
+	// an explicit false overrides true.
+	IncludeZeroValueCalls *bool `toml:"include_zero_value_calls,omitempty"`
+
+	// TLS is enabled when the certificate and key files are populated,
+	// client certificates are verified when the client CA file is.
+	TLSCertFile     string `toml:"tls_cert_file,omitempty"`
+	TLSKeyFile      string `toml:"tls_key_file,omitempty"`
+	TLSClientCAFile string `toml:"tls_client_ca_file,omitempty"`
+
+	// Authentication is enabled when API keys are populated, the public
+	// groups are then the endpoint groups open to any client.
+	APIKeys      []*APIKey `toml:"api_keys,omitempty"`
+	PublicGroups []string  `toml:"public_groups,omitempty"`
+
+	// RateLimit is the number of requests per second allowed per
+	// api key, or per IP without api key. Zero disables rate limiting.
+	RateLimit *float64 `toml:"rate_limit,omitempty"`
+	RateBurst int      `toml:"rate_burst,omitempty"`
+
+	// Requests to the data endpoints and to the construction endpoints
+	// are served by separate pools so that one never starves the other.
+	DataConcurrency         *int `toml:"data_concurrency,omitempty"`
+	DataQueueSize           *int `toml:"data_queue_size,omitempty"`
+	ConstructionConcurrency *int `toml:"construction_concurrency,omitempty"`
+	ConstructionQueueSize   *int `toml:"construction_queue_size,omitempty"`
+}
+
+// LoadSettings merges the settings of the config file, the environment
+// and the flags, then populates the unset settings with their defaults.
+// If configFile is empty, the config file is read from CONFIG_FILE.
+func LoadSettings(configFile string, flags *Settings) (*Settings, error) {
+	if len(configFile) == 0 {
+		configFile = os.Getenv(ConfigFileEnv)
+	}
+
+	settings := &Settings{}
+	if len(configFile) > 0 {
+		fileSettings, err := LoadSettingsFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		settings.merge(fileSettings)
+	}
+
+	envSettings, err := settingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	settings.merge(envSettings)
+
+	if flags != nil {
+		settings.merge(flags)
+	}
+
+	settings.populateDefaults()
+	return settings, nil
+}
+
+// LoadSettingsFile reads the settings of a TOML config file.
+// Unknown settings are rejected.
+func LoadSettingsFile(configFile string) (*Settings, error) {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read config file %s", err, configFile)
+	}
+
+	settings := &Settings{}
+	if err := toml.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse config file %s", err, configFile)
+	}
+
+	return settings, nil
+}
+
+// String returns the settings in the format of the config file,
+// without the API keys.
+func (s *Settings) String() string {
+	redacted := *s
+	redacted.APIKeys = redactAPIKeys(s.APIKeys)
+	data, err := toml.Marshal(redacted)
+	if err != nil {
+		return err.Error()
+	}
+	return string(bytes.TrimSpace(data))
+}
+
+// settingsFromEnv reads the settings populated in the environment.
+func settingsFromEnv() (*Settings, error) {
+	settings := &Settings{
+		Mode:      os.Getenv(ModeEnv),
+		Network:   os.Getenv(NetworkEnv),
+		Tomo:      os.Getenv(TomoEnv),
+		ChainSpec: os.Getenv(ChainSpecEnv),
+		TipPolicy: os.Getenv(TipPolicyEnv),
+	}
+
+	if portValue := os.Getenv(PortEnv); len(portValue) > 0 {
+		port, err := strconv.Atoi(portValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse port %s", err, portValue)
+		}
+		settings.Port = port
+	}
+
+	if confirmationsValue := os.Getenv(TipConfirmationsEnv); len(confirmationsValue) > 0 {
+		confirmations, err := strconv.ParseUint(confirmationsValue, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse tip confirmations %s", err, confirmationsValue)
+		}
+		settings.TipConfirmations = &confirmations
+	}
+
+	if stalenessValue := os.Getenv(SyncStalenessEnv); len(stalenessValue) > 0 {
+		staleness, err := strconv.ParseUint(stalenessValue, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse sync staleness %s", err, stalenessValue)
+		}
+		settings.SyncStaleness = &staleness
+	}
+
+	return settings, nil
+}
+
+// merge overrides the settings with the settings populated in other.
+func (s *Settings) merge(other *Settings) {
+	if len(other.Mode) > 0 {
+		s.Mode = other.Mode
+	}
+	if len(other.Network) > 0 {
+		s.Network = other.Network
+	}
+	if other.Port != 0 {
+		s.Port = other.Port
+	}
+	if len(other.Tomo) > 0 {
+		s.Tomo = other.Tomo
+	}
+	if len(other.ChainSpec) > 0 {
+		s.ChainSpec = other.ChainSpec
+	}
+	if len(other.TipPolicy) > 0 {
+		s.TipPolicy = other.TipPolicy
+	}
+	if other.TipConfirmations != nil {
+		s.TipConfirmations = other.TipConfirmations
+	}
+	if other.SyncStaleness != nil {
+		s.SyncStaleness = other.SyncStaleness
+	}
+	if len(other.ReadTimeout) > 0 {
+		s.ReadTimeout = other.ReadTimeout
+	}
+	if len(other.WriteTimeout) > 0 {
+		s.WriteTimeout = other.WriteTimeout
+	}
+	if len(other.IdleTimeout) > 0 {
+		s.IdleTimeout = other.IdleTimeout
+	}
+	if len(other.TomoTimeout) > 0 {
+		s.TomoTimeout = other.TomoTimeout
+	}
+	if len(other.TracerTimeout) > 0 {
+		s.TracerTimeout = other.TracerTimeout
+	}
+	if len(other.TracerFile) > 0 {
+		s.TracerFile = other.TracerFile
+	}
+	if len(other.MaxHeadAge) > 0 {
+		s.MaxHeadAge = other.MaxHeadAge
+	}
+	if len(other.LogFormat) > 0 {
+		s.LogFormat = other.LogFormat
+	}
+	if len(other.LogLevel) > 0 {
+		s.LogLevel = other.LogLevel
+	}
+	if other.MaxTraceConcurrency != nil {
+		s.MaxTraceConcurrency = other.MaxTraceConcurrency
+	}
+	if len(other.TomoBinary) > 0 {
+		s.TomoBinary = other.TomoBinary
+	}
+	if other.IncludeZeroValueCalls != nil {
+		s.IncludeZeroValueCalls = other.IncludeZeroValueCalls
+	}
+	if len(other.TLSCertFile) > 0 {
+		s.TLSCertFile = other.TLSCertFile
+	}
+	if len(other.TLSKeyFile) > 0 {
+		s.TLSKeyFile = other.TLSKeyFile
+	}
+	if len(other.TLSClientCAFile) > 0 {
+		s.TLSClientCAFile = other.TLSClientCAFile
+	}
+	if len(other.APIKeys) > 0 {
+		s.APIKeys = other.APIKeys
+	}
+	if len(other.PublicGroups) > 0 {
+		s.PublicGroups = other.PublicGroups
+	}
+	if other.RateLimit != nil {
+		s.RateLimit = other.RateLimit
+	}
+	if other.RateBurst != 0 {
+		s.RateBurst = other.RateBurst
+	}
+	if other.DataConcurrency != nil {
+		s.DataConcurrency = other.DataConcurrency
+	}
+	if other.DataQueueSize != nil {
+		s.DataQueueSize = other.DataQueueSize
+	}
+	if other.ConstructionConcurrency != nil {
+		s.ConstructionConcurrency = other.ConstructionConcurrency
+	}
+	if other.ConstructionQueueSize != nil {
+		s.ConstructionQueueSize = other.ConstructionQueueSize
+	}
+}
+
+func (s *Settings) populateDefaults() {
+	if len(s.ReadTimeout) == 0 {
+		s.ReadTimeout = DefaultReadTimeout
+	}
+	if len(s.WriteTimeout) == 0 {
+		s.WriteTimeout = DefaultWriteTimeout
+	}
+	if len(s.IdleTimeout) == 0 {
+		s.IdleTimeout = DefaultIdleTimeout
+	}
+	if len(s.TomoTimeout) == 0 {
+		s.TomoTimeout = DefaultTomoTimeout
+	}
+	if len(s.TracerTimeout) == 0 {
+		s.TracerTimeout = DefaultTracerTimeout
+	}
+	if len(s.MaxHeadAge) == 0 {
+		s.MaxHeadAge = DefaultMaxHeadAge
+	}
+	if len(s.LogFormat) == 0 {
+		s.LogFormat = DefaultLogFormat
+	}
+	if len(s.LogLevel) == 0 {
+		s.LogLevel = DefaultLogLevel
+	}
+	if s.MaxTraceConcurrency == nil {
+		maxTraceConcurrency := int64(DefaultMaxTraceConcurrency)
+		s.MaxTraceConcurrency = &maxTraceConcurrency
+	}
+	if len(s.TomoBinary) == 0 {
+		s.TomoBinary = DefaultTomoBinary
+	}
+	if len(s.TipPolicy) == 0 {
+		s.TipPolicy = tomochain.TipLatest
+	}
+	if s.RateLimit != nil && *s.RateLimit > 0 && s.RateBurst == 0 {
+		s.RateBurst = int(math.Ceil(*s.RateLimit))
+	}
+	populateInt(&s.DataConcurrency, DefaultDataConcurrency)
+	populateInt(&s.DataQueueSize, DefaultQueueSize)
+	populateInt(&s.ConstructionConcurrency, DefaultConstructionConcurrency)
+	populateInt(&s.ConstructionQueueSize, DefaultQueueSize)
+}
+
+// populateInt populates an unset setting with its default.
+func populateInt(setting **int, defaultValue int) {
+	if *setting == nil {
+		*setting = &defaultValue
+	}
+}
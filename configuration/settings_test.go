@@ -0,0 +1,183 @@
+// Copyright (c) 2020 TomoChain
+
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// writeConfigFile writes a temporary config file, to be removed by the caller.
+func writeConfigFile(t *testing.T, data string) string {
+	file, err := ioutil.TempFile("", "tomochain-rosetta-*.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestLoadSettingsIncludeZeroValueCalls(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		flag *bool
+		want *bool
+	}{
+		{name: "unset"},
+		{name: "file", file: "include_zero_value_calls = true", want: boolPtr(true)},
+		{name: "flag", flag: boolPtr(true), want: boolPtr(true)},
+		{name: "explicit false flag", file: "include_zero_value_calls = true", flag: boolPtr(false), want: boolPtr(false)},
+		{name: "explicit false file", file: "include_zero_value_calls = false", want: boolPtr(false)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configFile := writeConfigFile(t, test.file)
+			defer os.Remove(configFile)
+
+			settings, err := LoadSettings(configFile, &Settings{IncludeZeroValueCalls: test.flag})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := settings.IncludeZeroValueCalls
+			if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+				t.Fatalf("include_zero_value_calls %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
+
+func TestLoadSettingsPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  string
+		flag *uint64
+		want *uint64
+	}{
+		{name: "unset"},
+		{name: "file", file: "5", want: uint64Ptr(5)},
+		{name: "env over file", file: "5", env: "7", want: uint64Ptr(7)},
+		{name: "flag over env", file: "5", env: "7", flag: uint64Ptr(9), want: uint64Ptr(9)},
+		{name: "explicit zero env", file: "5", env: "0", want: uint64Ptr(0)},
+		{name: "explicit zero flag", file: "5", env: "7", flag: uint64Ptr(0), want: uint64Ptr(0)},
+		{name: "explicit zero file", file: "0", want: uint64Ptr(0)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data string
+			if len(test.file) > 0 {
+				data = "tip_confirmations = " + test.file + "\nsync_staleness = " + test.file
+			}
+			configFile := writeConfigFile(t, data)
+			defer os.Remove(configFile)
+			for _, env := range []string{TipConfirmationsEnv, SyncStalenessEnv} {
+				if len(test.env) > 0 {
+					os.Setenv(env, test.env)
+				} else {
+					os.Unsetenv(env)
+				}
+				defer os.Unsetenv(env)
+			}
+
+			settings, err := LoadSettings(configFile, &Settings{TipConfirmations: test.flag, SyncStaleness: test.flag})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, got := range map[string]*uint64{
+				"tip_confirmations": settings.TipConfirmations,
+				"sync_staleness":    settings.SyncStaleness,
+			} {
+				if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+					t.Errorf("%s %v, want %v", name, got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadSettingsExplicitZero(t *testing.T) {
+	configFile := writeConfigFile(t, `
+max_trace_concurrency = 4
+rate_limit = 10.0
+data_concurrency = 4
+data_queue_size = 8
+construction_concurrency = 4
+construction_queue_size = 8
+`)
+	defer os.Remove(configFile)
+
+	zero, zeroInt64, zeroFloat64 := 0, int64(0), float64(0)
+	settings, err := LoadSettings(configFile, &Settings{
+		MaxTraceConcurrency:     &zeroInt64,
+		RateLimit:               &zeroFloat64,
+		DataConcurrency:         &zero,
+		DataQueueSize:           &zero,
+		ConstructionConcurrency: &zero,
+		ConstructionQueueSize:   &zero,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *settings.MaxTraceConcurrency != 0 {
+		t.Errorf("max_trace_concurrency %d, want 0", *settings.MaxTraceConcurrency)
+	}
+	if *settings.RateLimit != 0 || settings.RateBurst != 0 {
+		t.Errorf("rate_limit %v and rate_burst %d, want 0", *settings.RateLimit, settings.RateBurst)
+	}
+	for name, got := range map[string]*int{
+		"data_concurrency":         settings.DataConcurrency,
+		"data_queue_size":          settings.DataQueueSize,
+		"construction_concurrency": settings.ConstructionConcurrency,
+		"construction_queue_size":  settings.ConstructionQueueSize,
+	} {
+		if *got != 0 {
+			t.Errorf("%s %d, want 0", name, *got)
+		}
+	}
+}
+
+func TestLoadSettingsDefaults(t *testing.T) {
+	configFile := writeConfigFile(t, "")
+	defer os.Remove(configFile)
+
+	settings, err := LoadSettings(configFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *settings.MaxTraceConcurrency != DefaultMaxTraceConcurrency {
+		t.Errorf("max_trace_concurrency %d, want %d", *settings.MaxTraceConcurrency, DefaultMaxTraceConcurrency)
+	}
+	if settings.RateLimit != nil || settings.RateBurst != 0 {
+		t.Errorf("rate_limit %v and rate_burst %d, want unset", settings.RateLimit, settings.RateBurst)
+	}
+	for name, test := range map[string]struct {
+		got  *int
+		want int
+	}{
+		"data_concurrency":         {settings.DataConcurrency, DefaultDataConcurrency},
+		"data_queue_size":          {settings.DataQueueSize, DefaultQueueSize},
+		"construction_concurrency": {settings.ConstructionConcurrency, DefaultConstructionConcurrency},
+		"construction_queue_size":  {settings.ConstructionQueueSize, DefaultQueueSize},
+	} {
+		if *test.got != test.want {
+			t.Errorf("%s %d, want %d", name, *test.got, test.want)
+		}
+	}
+}
@@ -3,15 +3,15 @@
 package main
 
 import (
-	"github.com/fatih/color"
 	"github.com/tomochain/tomochain-rosetta-gateway/cmd"
+	"github.com/tomochain/tomochain/log"
 	"os"
 )
 
 func main() {
 	err := cmd.Execute()
 	if err != nil {
-		color.Red(err.Error())
+		log.Error("tomochain-rosetta exited", "err", err)
 		os.Exit(1)
 	}
 }
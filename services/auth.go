@@ -0,0 +1,115 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain-rosetta-gateway/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// APIKeyHeader is the header carrying the api key of a request,
+	// which can also be sent as a bearer token.
+	APIKeyHeader = "X-API-Key"
+)
+
+type apiKeyNameKey struct{}
+
+// EndpointGroup returns the group of the endpoint at path.
+func EndpointGroup(path string) string {
+	switch {
+	case path == "/construction/submit":
+		return configuration.SubmitGroup
+	case strings.HasPrefix(path, "/construction/"):
+		return configuration.ConstructionGroup
+	default:
+		return configuration.DataGroup
+	}
+}
+
+// AuthMiddleware rejects the requests to endpoint groups which are not
+// public when they do not carry an api key granting access to the group.
+func AuthMiddleware(cfg *configuration.Configuration, next http.Handler) http.Handler {
+	public := map[string]bool{}
+	for _, group := range cfg.PublicGroups {
+		public[group] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group := EndpointGroup(r.URL.Path)
+		key := requestAPIKey(r)
+		if len(key) == 0 {
+			if !public[group] {
+				writeError(w, http.StatusUnauthorized, common.ErrUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		apiKey := findAPIKey(cfg.APIKeys, key)
+		if apiKey == nil {
+			writeError(w, http.StatusUnauthorized, common.ErrUnauthorized)
+			return
+		}
+		if !public[group] && !hasGroup(apiKey.Groups, group) {
+			writeError(w, http.StatusForbidden, common.ErrForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), apiKeyNameKey{}, apiKey.Name)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// APIKeyName returns the name of the api key
+// which authenticated the request of ctx, if any.
+func APIKeyName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(apiKeyNameKey{}).(string)
+	return name, ok
+}
+
+// requestAPIKey returns the api key of the X-API-Key header or the bearer token.
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); len(key) > 0 {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return ""
+}
+
+func findAPIKey(keys []*configuration.APIKey, key string) *configuration.APIKey {
+	var found *configuration.APIKey
+	for _, apiKey := range keys {
+		// compare all keys in constant time
+		if subtle.ConstantTimeCompare([]byte(apiKey.Key), []byte(key)) == 1 {
+			found = apiKey
+		}
+	}
+	return found
+}
+
+func hasGroup(groups []string, group string) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError writes a Rosetta error with the given HTTP status.
+func writeError(w http.ResponseWriter, status int, err *types.Error) {
+	server.EncodeJSONResponse(err, status, w)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+)
+
+// blockMetadataPaths are the endpoints parsing blocks, whose
+// requests can carry metadata changing how blocks are parsed.
+var blockMetadataPaths = map[string]bool{
+	"/block":             true,
+	"/block/transaction": true,
+}
+
+// blockMetadataRequest is the metadata of a request to an endpoint
+// parsing blocks. The Rosetta block requests have no metadata field,
+// so it is read from the request body before the request is decoded.
+type blockMetadataRequest struct {
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// BlockMetadataMiddleware reads the metadata of the requests parsing
+// blocks into their context. The "include_zero_value_calls" metadata
+// overrides the include_zero_value_calls setting for the request.
+func BlockMetadataMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !blockMetadataPaths[r.URL.Path] || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, common.WrapErr(common.ErrMalformedValue, err))
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// malformed requests are rejected when they are decoded
+		var request blockMetadataRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if value, ok := request.Metadata[common.METADATA_INCLUDE_ZERO_VALUE_CALLS]; ok {
+			include, ok := value.(bool)
+			if !ok {
+				writeError(w, http.StatusInternalServerError, common.WrapErr(
+					common.ErrMalformedValue,
+					fmt.Errorf("metadata %s must be a boolean", common.METADATA_INCLUDE_ZERO_VALUE_CALLS),
+				))
+				return
+			}
+			ctx = tomochain.WithZeroValueCalls(ctx, include)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
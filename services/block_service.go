@@ -38,11 +38,8 @@ func (s *BlockAPIService) Block(
 	}
 
 	block, err := s.client.Block(ctx, request.BlockIdentifier)
-	if errors.Is(err, tomochain.ErrBlockOrphaned) {
-		return nil, common.ErrBlockOrphaned
-	}
 	if err != nil {
-		return nil, common.ErrTomoNotReady
+		return nil, blockError(ctx, err, request.BlockIdentifier)
 	}
 
 	return &types.BlockResponse{
@@ -50,13 +47,43 @@ func (s *BlockAPIService) Block(
 	}, nil
 }
 
-
 // BlockTransaction implements the /block/transaction endpoint.
-// Note: we don't implement this, since we already return all transactions
-// in the /block endpoint reponse above.
+// The reward transaction of a checkpoint block is identified
+// by tomochain.RewardTransactionHash of the block hash.
 func (s *BlockAPIService) BlockTransaction(
 	ctx context.Context,
 	request *types.BlockTransactionRequest,
 ) (*types.BlockTransactionResponse, *types.Error) {
-	return nil, common.ErrNotImplemented
+	if s.config.Mode != configuration.Online {
+		return nil, common.ErrUnavailableOffline
+	}
+
+	tx, err := s.client.Transaction(ctx, request.BlockIdentifier, request.TransactionIdentifier)
+	if errors.Is(err, tomochain.ErrTransactionNotFound) {
+		return nil, common.WrapErr(common.ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, blockError(ctx, err, request.BlockIdentifier)
+	}
+
+	return &types.BlockTransactionResponse{
+		Transaction: tx,
+	}, nil
+}
+
+// blockError returns the Rosetta error of a
+// failure to parse a block from tomo.
+func blockError(ctx context.Context, err error, blockIdentifier interface{}) *types.Error {
+	switch {
+	case errors.Is(err, tomochain.ErrBlockOrphaned):
+		return common.ErrBlockOrphaned
+	case errors.Is(err, tomochain.ErrRewardsNotStored):
+		return common.WrapErr(common.ErrRewardsUnavailable, err)
+	case errors.Is(err, tomochain.ErrInvalidAddress) || errors.Is(err, tomochain.ErrNegativeBalance):
+		// tomo returned data the block cannot be parsed from
+		common.Logger(ctx).Error("unable to parse block", "block", blockIdentifier, "err", err)
+		return common.WrapErr(common.ErrTomo, err)
+	default:
+		return common.ErrTomoNotReady
+	}
 }
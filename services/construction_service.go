@@ -5,7 +5,6 @@ package services
 import (
 	"context"
 	"encoding/hex"
-	"fmt"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/spf13/cast"
 	"github.com/tomochain/tomochain-rosetta-gateway/common"
@@ -55,29 +54,29 @@ func (s *ConstructionAPIService) ConstructionCombine(
 ) (*types.ConstructionCombineResponse, *types.Error) {
 	b, err := hex.DecodeString(request.UnsignedTransaction)
 	if err != nil {
-		fmt.Println("construction/combine: unable to decode unsigned transaction", err)
+		common.Logger(ctx).Warn("construction/combine: unable to decode unsigned transaction", "err", err)
 		return nil, common.ErrInvalidInputParam
 	}
 	unsignTx := &transaction{}
 	err = rlp.DecodeBytes(b, unsignTx)
 	if err != nil {
-		fmt.Println("construction/combine: unable to decode unsigned transaction", err)
+		common.Logger(ctx).Warn("construction/combine: unable to decode unsigned transaction", "err", err)
 		return nil, common.ErrInvalidInputParam
 	}
 	if len(request.Signatures) != 1 {
-		fmt.Println("construction/combine: need exact 1 signature", len(request.Signatures))
+		common.Logger(ctx).Warn("construction/combine: need exact 1 signature", "signatures", len(request.Signatures))
 		return nil, common.ErrInvalidInputParam
 	}
 
 	rawSig := request.Signatures[0].Bytes
 	if len(rawSig) != 65 {
-		fmt.Println("construction/combine: invalid signature length", len(rawSig))
+		common.Logger(ctx).Warn("construction/combine: invalid signature length", "length", len(rawSig))
 		return nil, common.ErrInvalidInputParam
 	}
 
 	chainId, err := strconv.Atoi(request.NetworkIdentifier.Network)
 	if err != nil {
-		fmt.Println("construction/combine: invalid network", err)
+		common.Logger(ctx).Warn("construction/combine: invalid network", "err", err)
 		return nil, common.ErrInvalidInputParam
 	}
 
@@ -91,12 +90,12 @@ func (s *ConstructionAPIService) ConstructionCombine(
 	)
 	signedTx, err := tomochainTransaction.WithSignature(tomochaintypes.NewEIP155Signer(big.NewInt(cast.ToInt64(chainId))), rawSig)
 	if err != nil {
-		fmt.Println("construction/combine: cannot sign transaction", err)
+		common.Logger(ctx).Error("construction/combine: cannot sign transaction", "err", err)
 		return nil, common.ErrServiceInternal
 	}
 	signedTxData, err := rlp.EncodeToBytes(signedTx)
 	if err != nil {
-		fmt.Println("construction/combine: cannot encode signed transaction", err)
+		common.Logger(ctx).Error("construction/combine: cannot encode signed transaction", "err", err)
 		return nil, common.ErrServiceInternal
 	}
 	return &types.ConstructionCombineResponse{
@@ -114,7 +113,7 @@ func (s *ConstructionAPIService) ConstructionDerive(
 	}
 
 	if len(request.PublicKey.Bytes) == 0 || request.PublicKey.CurveType != types.Secp256k1 {
-		fmt.Println("/construction/derive: unsupported public key type", request.PublicKey.CurveType, len(request.PublicKey.Bytes))
+		common.Logger(ctx).Warn("construction/derive: unsupported public key type", "curve", request.PublicKey.CurveType, "length", len(request.PublicKey.Bytes))
 		return nil, common.ErrInvalidInputParam
 	}
 	pubkey, err := crypto.DecompressPubkey(request.PublicKey.Bytes)
@@ -141,13 +140,13 @@ func (s *ConstructionAPIService) ConstructionHash(
 	}
 	tran, err := hex.DecodeString(request.SignedTransaction)
 	if err != nil {
-		fmt.Println("/construction/hash: invalid signed transaction format", err)
+		common.Logger(ctx).Warn("construction/hash: invalid signed transaction format", "signed_transaction", common.RedactPayload(request.SignedTransaction), "err", err)
 		return nil, common.ErrInvalidInputParam
 	}
 	tx := &tomochaintypes.Transaction{}
 	err = rlp.DecodeBytes(tran, tx)
 	if err != nil {
-		fmt.Println("/construction/hash: unable to decode signed transaction ", err)
+		common.Logger(ctx).Warn("construction/hash: unable to decode signed transaction", "signed_transaction", common.RedactPayload(request.SignedTransaction), "err", err)
 		return nil, common.ErrInvalidInputParam
 	}
 
@@ -166,16 +165,16 @@ func (s *ConstructionAPIService) ConstructionHash(
 // value (uint64)
 // data ([]bytes) : data include method name, argument if this tx call a contract
 
-func parseMetaDataToCallMsg(options map[string]interface{}) (common.CallArgs, *types.Error) {
+func parseMetaDataToCallMsg(ctx context.Context, options map[string]interface{}) (common.CallArgs, *types.Error) {
 	sender, ok := options[common.METADATA_SENDER]
 	if !ok {
-		fmt.Println("parseMetaDataToCallMsg: empty sender address")
+		common.Logger(ctx).Warn("construction/metadata: empty sender address")
 		return common.CallArgs{}, common.ErrInvalidInputParam
 	}
 
 	to, ok := options[common.METADATA_RECIPIENT]
 	if !ok {
-		fmt.Println("parseMetaDataToCallMsg: empty recipient address")
+		common.Logger(ctx).Warn("construction/metadata: empty recipient address")
 		return common.CallArgs{}, common.ErrInvalidInputParam
 	}
 	destinationAddress := tomochaincommon.HexToAddress(cast.ToString(to))
@@ -226,20 +225,20 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, terr
 	}
 
-	callMsg, terr := parseMetaDataToCallMsg(request.Options)
+	callMsg, terr := parseMetaDataToCallMsg(ctx, request.Options)
 	if terr != nil {
 		return nil, terr
 	}
 	estimateGas, err := s.client.EstimateGas(ctx, callMsg)
 	if err != nil {
-		fmt.Println("construction/metadata: failed to estimate gas", err)
+		common.Logger(ctx).Warn("construction/metadata: failed to estimate gas", "err", err)
 		return nil, common.ErrUnableToEstimateGas
 	}
 	account, err := s.client.Balance(ctx, &types.AccountIdentifier{
 		Address: callMsg.From.String(),
 	}, nil)
 	if err != nil {
-		fmt.Println("construction/metadata: failed to getAccount", callMsg.From.String(), err)
+		common.Logger(ctx).Warn("construction/metadata: failed to get account", "address", callMsg.From.String(), "err", err)
 		return nil, common.ErrUnableToGetAccount
 	}
 	meta := account.Metadata
@@ -276,12 +275,12 @@ func (s *ConstructionAPIService) ConstructionParse(
 		// decode unsigned transaction
 		b, err := hex.DecodeString(request.Transaction)
 		if err != nil {
-			fmt.Println("construction/parse: failed to decode transaction", err)
+			common.Logger(ctx).Warn("construction/parse: failed to decode transaction", "transaction", common.RedactPayload(request.Transaction), "err", err)
 			return nil, common.ErrUnableToParseTx
 		}
 		err = rlp.DecodeBytes(b, tx)
 		if err != nil {
-			fmt.Println("construction/parse: failed to decode transaction", err)
+			common.Logger(ctx).Warn("construction/parse: failed to decode transaction", "transaction", common.RedactPayload(request.Transaction), "err", err)
 			return nil, common.ErrUnableToParseTx
 		}
 	} else {
@@ -289,12 +288,12 @@ func (s *ConstructionAPIService) ConstructionParse(
 		t := new(tomochaintypes.Transaction)
 		b, err := hex.DecodeString(request.Transaction)
 		if err != nil {
-			fmt.Println("construction/parse: failed to decode transaction", err)
+			common.Logger(ctx).Warn("construction/parse: failed to decode transaction", "transaction", common.RedactPayload(request.Transaction), "err", err)
 			return nil, common.ErrUnableToParseTx
 		}
 		err = rlp.DecodeBytes(b, t)
 		if err != nil {
-			fmt.Println("construction/parse: failed to decode transaction", err)
+			common.Logger(ctx).Warn("construction/parse: failed to decode transaction", "transaction", common.RedactPayload(request.Transaction), "err", err)
 			return nil, common.ErrUnableToParseTx
 		}
 
@@ -308,7 +307,7 @@ func (s *ConstructionAPIService) ConstructionParse(
 
 		msg, err := t.AsMessage(tomochaintypes.NewEIP155Signer(t.ChainId()), nil, nil)
 		if err != nil {
-			fmt.Println("construction/parse: unable to GetAccount", err)
+			common.Logger(ctx).Warn("construction/parse: unable to get sender", "err", err)
 			return nil, common.ErrUnableToGetAccount
 		}
 		tx.From = msg.From().String()
@@ -317,14 +316,14 @@ func (s *ConstructionAPIService) ConstructionParse(
 	// Ensure valid from address
 	ok := tomochaincommon.IsHexAddress(tx.From)
 	if !ok {
-		fmt.Printf("construction/parse: %s is not a valid address", tx.From)
+		common.Logger(ctx).Warn("construction/parse: invalid sender address", "address", tx.From)
 		return nil, common.ErrUnableToGetAccount
 	}
 
 	// Ensure valid to address
 	ok = tomochaincommon.IsHexAddress(tx.From)
 	if !ok {
-		fmt.Printf("construction/parse: %s is not a valid address", tx.To)
+		common.Logger(ctx).Warn("construction/parse: invalid recipient address", "address", tx.To)
 		return nil, common.ErrUnableToGetAccount
 	}
 
@@ -395,14 +394,14 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 	request *types.ConstructionPayloadsRequest,
 ) (*types.ConstructionPayloadsResponse, *types.Error) {
 	if len(request.Operations) != 2 {
-		fmt.Println("construction/payloads: ConstructionPayloadsRequest require 2 operations", len(request.Operations))
+		common.Logger(ctx).Warn("construction/payloads: need exact 2 operations", "operations", len(request.Operations))
 		return nil, common.ErrInvalidInputParam
 	}
 	addr := request.Operations[0].Account.Address
 
 	nonce, ok := request.Metadata[common.METADATA_ACCOUNT_SEQUENCE]
 	if !ok || nonce == nil {
-		fmt.Println("construction/payloads: failed to getNextNonce from metadata", addr)
+		common.Logger(ctx).Warn("construction/payloads: failed to get nonce from metadata", "address", addr)
 		return nil, common.ErrUnableToGetNextNonce
 	}
 	txValue, _ := new(big.Int).SetString(cast.ToString(request.Operations[1].Amount.Value), 10)
@@ -436,7 +435,7 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 
 	d, err := rlp.EncodeToBytes(unsignedTx)
 	if err != nil {
-		fmt.Println("construction/payloads: failed to encode transaction", err)
+		common.Logger(ctx).Error("construction/payloads: failed to encode transaction", "err", err)
 		return nil, common.ErrServiceInternal
 	}
 	unsignedTxEncode := hex.EncodeToString(d)
@@ -502,13 +501,13 @@ func (s *ConstructionAPIService) ConstructionSubmit(
 
 	tran, err := hex.DecodeString(request.SignedTransaction)
 	if err != nil {
-		fmt.Println("construction/submit: failed to decode transaction", err)
+		common.Logger(ctx).Warn("construction/submit: failed to decode transaction", "signed_transaction", common.RedactPayload(request.SignedTransaction), "err", err)
 		return nil, common.ErrUnableToParseTx
 	}
 
 	txID, err := s.client.SubmitTx(ctx, tran)
 	if err != nil {
-		fmt.Println("construction/submit: failed to submit transaction", err)
+		common.Logger(ctx).Warn("construction/submit: failed to submit transaction", "signed_transaction", common.RedactPayload(request.SignedTransaction), "err", err)
 		return nil, common.ErrUnableToSubmitTx
 	}
 
@@ -0,0 +1,161 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain-rosetta-gateway/configuration"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+)
+
+const (
+	// LivenessPath is the path of the liveness probe.
+	LivenessPath = "/healthz"
+
+	// ReadinessPath is the path of the readiness probe.
+	ReadinessPath = "/readyz"
+)
+
+type (
+	// Health reports whether tomochain-rosetta is alive and ready to serve
+	// requests. It is ready once the startup checks passed and at least
+	// one upstream node is reachable, synced, recent and on the
+	// configured network.
+	Health struct {
+		cfg    *configuration.Configuration
+		client *tomochain.Client
+
+		tomoReady int32
+		started   int32
+	}
+
+	// ReadinessResponse is the body of the readiness probe.
+	ReadinessResponse struct {
+		Ready     bool                        `json:"ready"`
+		Reasons   []string                    `json:"reasons,omitempty"`
+		Upstreams []*tomochain.UpstreamHealth `json:"upstreams,omitempty"`
+	}
+)
+
+// NewHealth returns the health of tomochain-rosetta, client is nil offline.
+func NewHealth(cfg *configuration.Configuration, client *tomochain.Client) *Health {
+	return &Health{
+		cfg:    cfg,
+		client: client,
+	}
+}
+
+// Started records that the startup checks passed.
+func (h *Health) Started() {
+	atomic.StoreInt32(&h.started, 1)
+}
+
+// Readiness reports whether tomochain-rosetta is ready,
+// and the reasons why it is not.
+func (h *Health) Readiness() *ReadinessResponse {
+	response := &ReadinessResponse{}
+	if h.client != nil {
+		response.Upstreams = h.client.UpstreamHealth()
+	}
+	if h.cfg.Mode == configuration.Online && !h.cfg.RemoteTomo && !h.tomoInitialized(response.Upstreams) {
+		response.Reasons = append(response.Reasons, "embedded tomo is initializing")
+	}
+	if atomic.LoadInt32(&h.started) == 0 {
+		response.Reasons = append(response.Reasons, "startup checks are running")
+	}
+	if h.client == nil {
+		response.Ready = len(response.Reasons) == 0
+		return response
+	}
+
+	var upstreamReasons []string
+	for _, upstream := range response.Upstreams {
+		reason := h.upstreamNotReady(upstream)
+		if len(reason) == 0 {
+			upstreamReasons = nil
+			break
+		}
+		upstreamReasons = append(upstreamReasons, fmt.Sprintf("tomo %s %s", upstream.URL, reason))
+	}
+	response.Reasons = append(response.Reasons, upstreamReasons...)
+	response.Ready = len(response.Reasons) == 0
+	return response
+}
+
+// tomoInitialized reports whether the embedded tomo is initialized, which
+// it is once its IPC endpoint answered a health check. Later failures are
+// reported by the upstream health.
+func (h *Health) tomoInitialized(upstreams []*tomochain.UpstreamHealth) bool {
+	if atomic.LoadInt32(&h.tomoReady) == 1 {
+		return true
+	}
+	for _, upstream := range upstreams {
+		if upstream.Checked && upstream.Reachable {
+			atomic.StoreInt32(&h.tomoReady, 1)
+			return true
+		}
+	}
+	return false
+}
+
+// upstreamNotReady returns why an upstream node cannot serve
+// requests, or an empty string if it can.
+func (h *Health) upstreamNotReady(upstream *tomochain.UpstreamHealth) string {
+	switch {
+	case !upstream.Checked:
+		return "is not checked yet"
+	case !upstream.Reachable:
+		return fmt.Sprintf("is unreachable: %s", upstream.Error)
+	case len(upstream.ChainID) > 0 && upstream.ChainID != h.cfg.Network.Network:
+		return fmt.Sprintf("chain ID %s does not match network %s", upstream.ChainID, h.cfg.Network.Network)
+	case len(upstream.Error) > 0:
+		return fmt.Sprintf("is unhealthy: %s", upstream.Error)
+	case upstream.Syncing:
+		return "is syncing"
+	}
+
+	if age := time.Since(upstream.HeadTime); age > h.cfg.MaxHeadAge {
+		return fmt.Sprintf("is lagging, block %d is %s old", upstream.Head, age.Truncate(time.Second))
+	}
+	return ""
+}
+
+// LivenessHandler answers the liveness probe, which
+// succeeds as long as the server is serving requests.
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.EncodeJSONResponse(map[string]string{"status": "ok"}, http.StatusOK, w)
+	})
+}
+
+// ReadinessHandler answers the readiness probe, it
+// fails with 503 when tomochain-rosetta is not ready.
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := h.Readiness()
+		status := http.StatusOK
+		if !response.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		server.EncodeJSONResponse(response, status, w)
+	})
+}
+
+// StartupMiddleware rejects the Rosetta requests
+// until the startup checks passed.
+func (h *Health) StartupMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&h.started) == 0 {
+			writeError(w, http.StatusServiceUnavailable, common.ErrNotReady)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
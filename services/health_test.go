@@ -0,0 +1,31 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"testing"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/configuration"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+)
+
+func TestTomoInitialized(t *testing.T) {
+	health := NewHealth(&configuration.Configuration{Mode: configuration.Online}, nil)
+
+	steps := []struct {
+		name      string
+		upstreams []*tomochain.UpstreamHealth
+		want      bool
+	}{
+		{name: "spawned"},
+		{name: "not checked", upstreams: []*tomochain.UpstreamHealth{{}}},
+		{name: "unreachable", upstreams: []*tomochain.UpstreamHealth{{Checked: true}}},
+		{name: "reachable", upstreams: []*tomochain.UpstreamHealth{{Checked: true, Reachable: true}}, want: true},
+		{name: "unreachable later", upstreams: []*tomochain.UpstreamHealth{{Checked: true}}, want: true},
+	}
+	for _, step := range steps {
+		if got := health.tomoInitialized(step.upstreams); got != step.want {
+			t.Fatalf("%s: initialized %v, want %v", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+)
+
+const (
+	// RequestIDHeader is the header carrying the ID of a request. The ID
+	// sent by a client is kept if it is valid, otherwise one is generated.
+	RequestIDHeader = "X-Request-ID"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware tags each request with an ID, which is returned in
+// the response headers and carried by the context of the request down to
+// the calls to tomo, so that their logs can be correlated.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(common.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// LoggerMiddleware writes a record per request served, at warn
+// level when the request failed. Request bodies are not logged
+// because they can carry signed transactions.
+func LoggerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+		next.ServeHTTP(recorder, r)
+
+		ctx := []interface{}{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.statusCode(),
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr,
+		}
+
+		if recorder.statusCode() != http.StatusOK {
+			ctx = append(ctx, "code", recorder.errorCode())
+			common.Logger(r.Context()).Warn("request failed", ctx...)
+			return
+		}
+		common.Logger(r.Context()).Info("request served", ctx...)
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+)
+
+const (
+	// MetricsPath is the path of the Prometheus metrics endpoint.
+	MetricsPath = "/metrics"
+
+	// unknownEndpoint labels the requests to paths which are not
+	// Rosetta routes, so that they do not grow the number of series.
+	unknownEndpoint = "unknown"
+
+	// noErrorCode labels the requests which did not return a Rosetta error.
+	noErrorCode = "none"
+
+	// maxErrorBodySize bounds the response body buffered
+	// to read the code of a Rosetta error.
+	maxErrorBodySize = 64 * 1024
+)
+
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: tomochain.MetricsNamespace,
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "Number of Rosetta requests by endpoint, HTTP status and Rosetta error code.",
+	}, []string{"endpoint", "status", "code"})
+
+	apiDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: tomochain.MetricsNamespace,
+		Subsystem: "api",
+		Name:      "duration_seconds",
+		Help:      "Latency of Rosetta requests by endpoint.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"endpoint"})
+)
+
+// rosettaRoutes are the paths served by NewBlockchainRouter,
+// the only values of the endpoint label besides unknownEndpoint.
+var rosettaRoutes = map[string]bool{
+	"/network/list":            true,
+	"/network/options":         true,
+	"/network/status":          true,
+	"/account/balance":         true,
+	"/account/coins":           true,
+	"/block":                   true,
+	"/block/transaction":       true,
+	"/construction/combine":    true,
+	"/construction/derive":     true,
+	"/construction/hash":       true,
+	"/construction/metadata":   true,
+	"/construction/parse":      true,
+	"/construction/payloads":   true,
+	"/construction/preprocess": true,
+	"/construction/submit":     true,
+	"/mempool":                 true,
+	"/mempool/transaction":     true,
+	"/call":                    true,
+}
+
+func init() {
+	prometheus.MustRegister(apiRequests, apiDuration)
+}
+
+// endpointLabel returns the endpoint label of a request path. Requests
+// can be answered before they are routed, by the authentication or the
+// rate limiting, so the label never depends on the status.
+func endpointLabel(path string) string {
+	if rosettaRoutes[path] {
+		return path
+	}
+	return unknownEndpoint
+}
+
+// responseRecorder records the status of a response, and its
+// body when it is an error.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	if recorder, ok := w.(*responseRecorder); ok {
+		return recorder
+	}
+	return &responseRecorder{ResponseWriter: w}
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status != http.StatusOK && w.body.Len()+len(b) <= maxErrorBodySize {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// statusCode returns the HTTP status of the response.
+func (w *responseRecorder) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// errorCode returns the code of the Rosetta error of the response.
+func (w *responseRecorder) errorCode() string {
+	if w.status == http.StatusOK || w.body.Len() == 0 {
+		return noErrorCode
+	}
+	var rosettaErr types.Error
+	if err := json.Unmarshal(w.body.Bytes(), &rosettaErr); err != nil {
+		return noErrorCode
+	}
+	return strconv.Itoa(int(rosettaErr.Code))
+}
+
+// MetricsMiddleware records the number, latency and
+// Rosetta error codes of the requests per endpoint.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+		next.ServeHTTP(recorder, r)
+
+		endpoint := endpointLabel(r.URL.Path)
+		status := recorder.statusCode()
+		apiDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		apiRequests.WithLabelValues(endpoint, strconv.Itoa(status), recorder.errorCode()).Inc()
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestRosettaRoutes(t *testing.T) {
+	routers := []server.Router{
+		server.NewNetworkAPIController(nil, nil),
+		server.NewAccountAPIController(nil, nil),
+		server.NewBlockAPIController(nil, nil),
+		server.NewConstructionAPIController(nil, nil),
+		server.NewMempoolAPIController(nil, nil),
+		server.NewCallAPIController(nil, nil),
+	}
+	routes := 0
+	for _, router := range routers {
+		for _, route := range router.Routes() {
+			routes++
+			if !rosettaRoutes[route.Pattern] {
+				t.Errorf("route %s is not labeled", route.Pattern)
+			}
+		}
+	}
+	if routes != len(rosettaRoutes) {
+		t.Errorf("%d routes are labeled, %d are served", len(rosettaRoutes), routes)
+	}
+}
+
+// endpointLabels returns the endpoint labels of the observed latencies.
+func endpointLabels(t *testing.T) map[string]bool {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		apiDuration.Collect(metrics)
+		close(metrics)
+	}()
+
+	labels := map[string]bool{}
+	for metric := range metrics {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatal(err)
+		}
+		for _, label := range m.Label {
+			labels[label.GetValue()] = true
+		}
+	}
+	return labels
+}
+
+func TestMetricsMiddlewareEndpointLabel(t *testing.T) {
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/block", http.StatusOK},
+		{"/block", http.StatusUnauthorized},
+		{"/random-1", http.StatusUnauthorized},
+		{"/random-2", http.StatusTooManyRequests},
+		{"/random-3", http.StatusServiceUnavailable},
+		{"/random-4", http.StatusNotFound},
+		{"/block/", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		status := test.status
+		handler := MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, test.path, nil))
+	}
+
+	labels := endpointLabels(t)
+	if len(labels) != 2 || !labels["/block"] || !labels[unknownEndpoint] {
+		t.Fatalf("endpoint labels %v, want /block and %s", labels, unknownEndpoint)
+	}
+}
@@ -37,18 +37,7 @@ func (s *NetworkAPIService) NetworkList(
 ) (*types.NetworkListResponse, *types.Error) {
 	return &types.NetworkListResponse{
 		NetworkIdentifiers: []*types.NetworkIdentifier{
-			{
-				Blockchain: common.TomoChainBlockchain,
-				Network:    strconv.FormatUint(common.TomoChainMainnetNetWorkId, 10),
-			},
-			{
-				Blockchain: common.TomoChainBlockchain,
-				Network:    strconv.FormatUint(common.TomoChainTestnetNetWorkId, 10),
-			},
-			{
-				Blockchain: common.TomoChainBlockchain,
-				Network:    strconv.FormatUint(common.TomoChainDevnetNetWorkId, 10),
-			},
+			s.config.Network,
 		},
 	}, nil
 }
@@ -58,12 +47,25 @@ func (s *NetworkAPIService) NetworkOptions(
 	ctx context.Context,
 	request *types.NetworkRequest,
 ) (*types.NetworkOptionsResponse, *types.Error) {
-	return &types.NetworkOptionsResponse{
-		Version: &types.Version{
-			RosettaVersion: types.RosettaAPIVersion,
-			MiddlewareVersion: &configuration.MiddlewareVersion,
-			NodeVersion:    params.Version,
+	version := &types.Version{
+		RosettaVersion: types.RosettaAPIVersion,
+		MiddlewareVersion: &configuration.MiddlewareVersion,
+		NodeVersion:    params.Version,
+		Metadata: map[string]interface{}{
+			common.METADATA_TRACER_SOURCE:  s.config.Tracer.Source,
+			common.METADATA_TRACER_VERSION: s.config.Tracer.Version,
 		},
+	}
+	if s.config.Mode == configuration.Online {
+		// checkpoint blocks cannot be served unless tomo stores
+		// their rewards, the field is omitted when tomo is unreachable
+		if storesRewards, err := s.client.StoresRewards(ctx); err == nil {
+			version.Metadata[common.METADATA_STORE_REWARD] = storesRewards
+		}
+	}
+
+	return &types.NetworkOptionsResponse{
+		Version: version,
 		Allow: &types.Allow{
 			OperationStatuses: []*types.OperationStatus{
 				{
@@ -78,7 +80,7 @@ func (s *NetworkAPIService) NetworkOptions(
 			OperationTypes:          common.SupportedOperationTypes(),
 			Errors:                  common.ErrorList,
 			HistoricalBalanceLookup: common.HistoricalBalanceSupported,
-			CallMethods:             tomochain.CallMethods,
+			CallMethods:             tomochain.DefaultCallRegistry.Names(),
 		},
 	}, nil
 }
@@ -0,0 +1,56 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain-rosetta-gateway/configuration"
+	"github.com/tomochain/tomochain-rosetta-gateway/tomochain"
+)
+
+// rewardsClient is a client which only reports whether tomo stores rewards.
+type rewardsClient struct {
+	Client
+	stores bool
+	err    error
+}
+
+func (c *rewardsClient) StoresRewards(context.Context) (bool, error) {
+	return c.stores, c.err
+}
+
+func TestNetworkOptionsStoreReward(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *rewardsClient
+		want   interface{}
+	}{
+		{name: "stored", client: &rewardsClient{stores: true}, want: true},
+		{name: "not stored", client: &rewardsClient{stores: false}, want: false},
+		{name: "unreachable", client: &rewardsClient{err: errors.New("connection refused")}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewNetworkAPIService(&configuration.Configuration{
+				Mode:   configuration.Online,
+				Tracer: &tomochain.Tracer{},
+			}, test.client)
+			response, err := service.NetworkOptions(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got, ok := response.Version.Metadata[common.METADATA_STORE_REWARD]
+			if test.want == nil && ok {
+				t.Fatalf("%s %v, want omitted", common.METADATA_STORE_REWARD, got)
+			}
+			if test.want != nil && got != test.want {
+				t.Fatalf("%s %v, want %v", common.METADATA_STORE_REWARD, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain-rosetta-gateway/configuration"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// idleLimiterTimeout is the time after which the
+	// limiter of a client which sent no request is dropped.
+	idleLimiterTimeout = 10 * time.Minute
+)
+
+type (
+	clientLimiter struct {
+		limiter  *rate.Limiter
+		lastSeen time.Time
+	}
+
+	// rateLimiter holds a token bucket per client.
+	rateLimiter struct {
+		sync.Mutex
+		limit     rate.Limit
+		burst     int
+		clients   map[string]*clientLimiter
+		lastSweep time.Time
+	}
+
+	// requestPool bounds the number of requests served at the same
+	// time and the number of requests waiting to be served.
+	requestPool struct {
+		running chan struct{}
+		queued  chan struct{}
+	}
+)
+
+// RateLimitMiddleware rejects the requests of a client exceeding its
+// request rate. Clients are identified by api key, or by IP without one.
+func RateLimitMiddleware(cfg *configuration.Configuration, next http.Handler) http.Handler {
+	if cfg.RateLimit <= 0 {
+		return next
+	}
+
+	limiter := &rateLimiter{
+		limit:   rate.Limit(cfg.RateLimit),
+		burst:   cfg.RateBurst,
+		clients: map[string]*clientLimiter{},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.allow(clientIdentity(r)) {
+			writeError(w, http.StatusTooManyRequests, common.ErrRateLimited)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIdentity returns the api key name of the request, or its remote IP.
+func clientIdentity(r *http.Request) string {
+	if name, ok := APIKeyName(r.Context()); ok {
+		return "key:" + name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func (l *rateLimiter) allow(client string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > idleLimiterTimeout {
+		for id, c := range l.clients {
+			if now.Sub(c.lastSeen) > idleLimiterTimeout {
+				delete(l.clients, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.clients[client]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = c
+	}
+	c.lastSeen = now
+	return c.limiter.AllowN(now, 1)
+}
+
+// AdmissionMiddleware serves the requests to the data endpoints and to the
+// construction endpoints from separate pools, so that heavy data requests
+// never starve construction requests. Requests are rejected with a
+// retriable error when the queue of their pool is full.
+func AdmissionMiddleware(cfg *configuration.Configuration, next http.Handler) http.Handler {
+	dataPool := newRequestPool(cfg.DataConcurrency, cfg.DataQueueSize)
+	constructionPool := newRequestPool(cfg.ConstructionConcurrency, cfg.ConstructionQueueSize)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pool := dataPool
+		if EndpointGroup(r.URL.Path) != configuration.DataGroup {
+			pool = constructionPool
+		}
+
+		release, ok := pool.acquire(r)
+		if !ok {
+			writeError(w, http.StatusServiceUnavailable, common.ErrServerBusy)
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newRequestPool(concurrency int, queueSize int) *requestPool {
+	return &requestPool{
+		running: make(chan struct{}, concurrency),
+		queued:  make(chan struct{}, concurrency+queueSize),
+	}
+}
+
+// acquire waits for the request to be served, it fails if the queue
+// is full or if the request is canceled while waiting.
+func (p *requestPool) acquire(r *http.Request) (func(), bool) {
+	select {
+	case p.queued <- struct{}{}:
+	default:
+		return nil, false
+	}
+
+	select {
+	case p.running <- struct{}{}:
+	case <-r.Context().Done():
+		<-p.queued
+		return nil, false
+	}
+
+	return func() {
+		<-p.running
+		<-p.queued
+	}, true
+}
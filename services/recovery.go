@@ -0,0 +1,42 @@
+// Copyright (c) 2020 TomoChain
+
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+)
+
+// RecoveryMiddleware turns a panic while serving a request into an
+// internal Rosetta error, so that a single bad request cannot stop
+// the server. The stack of the panic is logged.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// the server aborts the response on purpose
+				panic(recovered)
+			}
+
+			common.Logger(r.Context()).Error(
+				"panic while serving request",
+				"path", r.URL.Path,
+				"panic", recovered,
+				"stack", string(debug.Stack()),
+			)
+			writeError(w, http.StatusInternalServerError, common.WrapErr(
+				common.ErrServiceInternal,
+				fmt.Errorf("panic: %v", recovered),
+			))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
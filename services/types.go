@@ -52,6 +52,16 @@ type Client interface {
 		ctx context.Context,
 		request *RosettaTypes.CallRequest,
 	) (*RosettaTypes.CallResponse, error)
+
+	// Transaction returns a transaction of a block.
+	Transaction(
+		context.Context,
+		*RosettaTypes.BlockIdentifier,
+		*RosettaTypes.TransactionIdentifier,
+	) (*RosettaTypes.Transaction, error)
+
+	// StoresRewards reports whether the node stores epoch rewards.
+	StoresRewards(context.Context) (bool, error)
 }
 
 type options struct {
@@ -1,8 +1,8 @@
 package tomochain
 
 import (
+	"fmt"
 	"github.com/tomochain/tomochain/common"
-	"log"
 )
 
 // ChecksumAddress ensures an Ethereum hex address
@@ -17,14 +17,14 @@ func ChecksumAddress(address string) (string, bool) {
 	return common.HexToAddress(address).Hex(), true
 }
 
-// MustChecksum ensures an address can be converted
-// into a valid checksum. If it does not, the program
-// will exit.
-func MustChecksum(address string) string {
+// Checksum ensures an address can be converted
+// into a valid checksum. If it does not, it returns
+// ErrInvalidAddress.
+func Checksum(address string) (string, error) {
 	addr, ok := ChecksumAddress(address)
 	if !ok {
-		log.Fatalf("invalid address %s", address)
+		return "", fmt.Errorf("%w: %s", ErrInvalidAddress, address)
 	}
 
-	return addr
+	return addr, nil
 }
@@ -0,0 +1,620 @@
+// Code generated by gen_assets.go. DO NOT EDIT.
+
+package tomochain
+
+// assets are the data files bundled into the binary, by path.
+var assets = map[string]string{
+	"call_tracer.js": `// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// callTracer is a full blown transaction tracer that extracts and reports all
+// the internal calls made by a transaction, along with any useful information.
+{
+	// callstack is the current recursive call stack of the EVM execution.
+	callstack: [{}],
+
+	// descended tracks whether we've just descended from an outer transaction into
+	// an inner call.
+	descended: false,
+
+	// step is invoked for every opcode that the VM executes.
+	step: function(log, db) {
+		// Capture any errors immediately
+		var error = log.getError();
+		if (error !== undefined) {
+			this.fault(log, db);
+			return;
+		}
+		// We only care about system opcodes, faster if we pre-check once
+		var syscall = (log.op.toNumber() & 0xf0) == 0xf0;
+		if (syscall) {
+			var op = log.op.toString();
+		}
+		// If a new contract is being created, add to the call stack
+		if (syscall && (op == 'CREATE' || op == "CREATE2")) {
+			var inOff = log.stack.peek(1).valueOf();
+			var inEnd = inOff + log.stack.peek(2).valueOf();
+
+			// Assemble the internal call report and store for completion
+			var call = {
+				type:    op,
+				from:    toHex(log.contract.getAddress()),
+				input:   toHex(log.memory.slice(inOff, inEnd)),
+				gasIn:   log.getGas(),
+				gasCost: log.getCost(),
+				value:   '0x' + log.stack.peek(0).toString(16)
+			};
+			this.callstack.push(call);
+			this.descended = true
+			return;
+		}
+		// If a contract is being self destructed, gather that as a subcall too
+		if (syscall && op == 'SELFDESTRUCT') {
+			var left = this.callstack.length;
+			if (this.callstack[left-1].calls === undefined) {
+				this.callstack[left-1].calls = [];
+			}
+			this.callstack[left-1].calls.push({type: op});
+			return
+		}
+		// If a new method invocation is being done, add to the call stack
+		if (syscall && (op == 'CALL' || op == 'CALLCODE' || op == 'DELEGATECALL' || op == 'STATICCALL')) {
+			// Skip any pre-compile invocations, those are just fancy opcodes
+			var to = toAddress(log.stack.peek(1).toString(16));
+			if (isPrecompiled(to)) {
+				return
+			}
+			var off = (op == 'DELEGATECALL' || op == 'STATICCALL' ? 0 : 1);
+
+			var inOff = log.stack.peek(2 + off).valueOf();
+			var inEnd = inOff + log.stack.peek(3 + off).valueOf();
+
+			// Assemble the internal call report and store for completion
+			var call = {
+				type:    op,
+				from:    toHex(log.contract.getAddress()),
+				to:      toHex(to),
+				input:   toHex(log.memory.slice(inOff, inEnd)),
+				gasIn:   log.getGas(),
+				gasCost: log.getCost(),
+				outOff:  log.stack.peek(4 + off).valueOf(),
+				outLen:  log.stack.peek(5 + off).valueOf()
+			};
+			if (op != 'DELEGATECALL' && op != 'STATICCALL') {
+				call.value = '0x' + log.stack.peek(2).toString(16);
+			}
+			this.callstack.push(call);
+			this.descended = true
+			return;
+		}
+		// If we've just descended into an inner call, retrieve it's true allowance. We
+		// need to extract if from within the call as there may be funky gas dynamics
+		// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+		if (this.descended) {
+			if (log.getDepth() >= this.callstack.length) {
+				this.callstack[this.callstack.length - 1].gas = log.getGas();
+			} else {
+				// TODO(karalabe): The call was made to a plain account. We currently don't
+				// have access to the true gas amount inside the call and so any amount will
+				// mostly be wrong since it depends on a lot of input args. Skip gas for now.
+			}
+			this.descended = false;
+		}
+		// If an existing call is returning, pop off the call stack
+		if (syscall && op == 'REVERT') {
+			this.callstack[this.callstack.length - 1].error = "execution reverted";
+			return;
+		}
+		if (log.getDepth() == this.callstack.length - 1) {
+			// Pop off the last call and get the execution results
+			var call = this.callstack.pop();
+
+			if (call.type == 'CREATE' || call.type == "CREATE2") {
+				// If the call was a CREATE, retrieve the contract address and output code
+				call.gasUsed = '0x' + bigInt(call.gasIn - call.gasCost - log.getGas()).toString(16);
+				delete call.gasIn; delete call.gasCost;
+
+				var ret = log.stack.peek(0);
+				if (!ret.equals(0)) {
+					call.to     = toHex(toAddress(ret.toString(16)));
+					call.output = toHex(db.getCode(toAddress(ret.toString(16))));
+				} else if (call.error === undefined) {
+					call.error = "internal failure"; // TODO(karalabe): surface these faults somehow
+				}
+			} else {
+				// If the call was a contract call, retrieve the gas usage and output
+				if (call.gas !== undefined) {
+					call.gasUsed = '0x' + bigInt(call.gasIn - call.gasCost + call.gas - log.getGas()).toString(16);
+
+					var ret = log.stack.peek(0);
+					if (!ret.equals(0)) {
+						call.output = toHex(log.memory.slice(call.outOff, call.outOff + call.outLen));
+					} else if (call.error === undefined) {
+						call.error = "internal failure"; // TODO(karalabe): surface these faults somehow
+					}
+				}
+				delete call.gasIn; delete call.gasCost;
+				delete call.outOff; delete call.outLen;
+			}
+			if (call.gas !== undefined) {
+				call.gas = '0x' + bigInt(call.gas).toString(16);
+			}
+			// Inject the call into the previous one
+			var left = this.callstack.length;
+			if (this.callstack[left-1].calls === undefined) {
+				this.callstack[left-1].calls = [];
+			}
+			this.callstack[left-1].calls.push(call);
+		}
+	},
+
+	// fault is invoked when the actual execution of an opcode fails.
+	fault: function(log, db) {
+		// If the topmost call already reverted, don't handle the additional fault again
+		if (this.callstack[this.callstack.length - 1].error !== undefined) {
+			return;
+		}
+		// Pop off the just failed call
+		var call = this.callstack.pop();
+		call.error = log.getError();
+
+		// Consume all available gas and clean any leftovers
+		if (call.gas !== undefined) {
+			call.gas = '0x' + bigInt(call.gas).toString(16);
+			call.gasUsed = call.gas
+		}
+		delete call.gasIn; delete call.gasCost;
+		delete call.outOff; delete call.outLen;
+
+		// Flatten the failed call into its parent
+		var left = this.callstack.length;
+		if (left > 0) {
+			if (this.callstack[left-1].calls === undefined) {
+				this.callstack[left-1].calls = [];
+			}
+			this.callstack[left-1].calls.push(call);
+			return;
+		}
+		// Last call failed too, leave it in the stack
+		this.callstack.push(call);
+	},
+
+	// result is invoked when all the opcodes have been iterated over and returns
+	// the final result of the tracing.
+	result: function(ctx, db) {
+		var result = {
+			type:    ctx.type,
+			from:    toHex(ctx.from),
+			to:      toHex(ctx.to),
+			value:   '0x' + ctx.value.toString(16),
+			gas:     '0x' + bigInt(ctx.gas).toString(16),
+			gasUsed: '0x' + bigInt(ctx.gasUsed).toString(16),
+			input:   toHex(ctx.input),
+			output:  toHex(ctx.output),
+			time:    ctx.time,
+		};
+		if (this.callstack[0].calls !== undefined) {
+			result.calls = this.callstack[0].calls;
+		}
+		if (this.callstack[0].error !== undefined) {
+			result.error = this.callstack[0].error;
+		} else if (ctx.error !== undefined) {
+			result.error = ctx.error;
+		}
+		if (result.error !== undefined) {
+			delete result.output;
+		}
+		return this.finalize(result);
+	},
+
+	// finalize recreates a call object using the final desired field oder for json
+	// serialization. This is a nicety feature to pass meaningfully ordered results
+	// to users who don't interpret it, just display it.
+	finalize: function(call) {
+		var sorted = {
+			type:    call.type,
+			from:    call.from,
+			to:      call.to,
+			value:   call.value,
+			gas:     call.gas,
+			gasUsed: call.gasUsed,
+			input:   call.input,
+			output:  call.output,
+			error:   call.error,
+			time:    call.time,
+			calls:   call.calls,
+		}
+		for (var key in sorted) {
+			if (sorted[key] === undefined) {
+				delete sorted[key];
+			}
+		}
+		if (sorted.calls !== undefined) {
+			for (var i=0; i<sorted.calls.length; i++) {
+				sorted.calls[i] = this.finalize(sorted.calls[i]);
+			}
+		}
+		return sorted;
+	}
+}
+`,
+	"special_rewards/mainnet.json": `{
+  "chain_id": 88,
+  "special_rewards": [
+    {
+      "block": 9073579,
+      "address": "0x5248bfb72fd4f234e062d3e9bb76f08643004fcd",
+      "amount": "29410000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145281,
+      "address": "0xd575c2611984fcd79513b80ab94f59dc5bab4916",
+      "amount": "28971000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145315,
+      "address": "0x0579337873c97c4ba051310236ea847f5be41bc0",
+      "amount": "28344000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145341,
+      "address": "0xed12a519cc15b286920fc15fd86106b3e6a16218",
+      "amount": "24443000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145367,
+      "address": "0x492d26d852a0a0a2982bb40ec86fe394488c419e",
+      "amount": "26623000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145386,
+      "address": "0xce5c7635d02dc4e1d6b46c256cae6323be294a32",
+      "amount": "28459000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145414,
+      "address": "0x8b94db158b5e78a6c032c7e7c9423dec62c8b11c",
+      "amount": "21803000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145436,
+      "address": "0x0e7c48c085b6b0aa7ca6e4cbcc8b9a92dc270eb4",
+      "amount": "21739000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145463,
+      "address": "0x206e6508462033ef8425edc6c10789d241d49acb",
+      "amount": "21883000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145493,
+      "address": "0x7710e7b7682f26cb5a1202e1cff094fbf7777758",
+      "amount": "28907000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145519,
+      "address": "0xcb06f949313b46bbf53b8e6b2868a0c260ff9385",
+      "amount": "28932000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9145549,
+      "address": "0xf884e43533f61dc2997c0e19a6eff33481920c00",
+      "amount": "27780000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147130,
+      "address": "0x5ac26105b35ea8935be382863a70281ec7a985e9",
+      "amount": "23551000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147195,
+      "address": "0x09c4f991a41e7ca0645d7dfbfee160b55e562ea4",
+      "amount": "25821000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147200,
+      "address": "0xb3157bbc5b401a45d6f60b106728bb82ebaa585b",
+      "amount": "20051000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147206,
+      "address": "0x741277a8952128d5c2ffe0550f5001e4c8247674",
+      "amount": "23937000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147212,
+      "address": "0x10ba49c1caa97d74b22b3e74493032b180cebe01",
+      "amount": "27320000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147217,
+      "address": "0x07048d51d9e6179578a6e3b9ee28cdc183b865e4",
+      "amount": "29758000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147223,
+      "address": "0x4b899001d73c7b4ec404a771d37d9be13b8983de",
+      "amount": "26148000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147229,
+      "address": "0x85cb320a9007f26b7652c19a2a65db1da2d0016f",
+      "amount": "27216000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147234,
+      "address": "0x06869dbd0e3a2ea37ddef832e20fa005c6f0ca39",
+      "amount": "29449000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147240,
+      "address": "0x82e48bc7e2c93d89125428578fb405947764ad7c",
+      "amount": "28084000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147246,
+      "address": "0x1f9a78534d61732367cbb43fc6c89266af67c989",
+      "amount": "29287000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147251,
+      "address": "0x7c3b1fa91df55ff7af0cad9e0399384dc5c6641b",
+      "amount": "21574000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147257,
+      "address": "0x5888dc1ceb0ff632713486b9418e59743af0fd20",
+      "amount": "28836000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147263,
+      "address": "0xa512fa1c735fc3cc635624d591dd9ea1ce339ca5",
+      "amount": "25515000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147268,
+      "address": "0x0832517654c7b7e36b1ef45d76de70326b09e2c7",
+      "amount": "22873000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147274,
+      "address": "0xca14e3c4c78bafb60819a78ff6e6f0f709d2aea7",
+      "amount": "24968000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147279,
+      "address": "0x652ce195a23035114849f7642b0e06647d13e57a",
+      "amount": "24091000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147285,
+      "address": "0x29a79f00f16900999d61b6e171e44596af4fb5ae",
+      "amount": "20790000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147291,
+      "address": "0xf9fd1c2b0af0d91b0b6754e55639e3f8478dd04a",
+      "amount": "23331000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147296,
+      "address": "0xb835710c9901d5fe940ef1b99ed918902e293e35",
+      "amount": "28273000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147302,
+      "address": "0x04dd29ce5c253377a9a3796103ea0d9a9e514153",
+      "amount": "29956000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147308,
+      "address": "0x2b4b56846eaf05c1fd762b5e1ac802efd0ab871c",
+      "amount": "24911000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147314,
+      "address": "0x1d1f909f6600b23ce05004f5500ab98564717996",
+      "amount": "25637000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147319,
+      "address": "0x0dfdcebf80006dc9ab7aae8c216b51c6b6759e86",
+      "amount": "26378000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147325,
+      "address": "0x2b373890a28e5e46197fbc04f303bbfdd344056f",
+      "amount": "21109000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147330,
+      "address": "0xa8a3ef3dc5d8e36aee76f3671ec501ec31e28254",
+      "amount": "22072000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147336,
+      "address": "0x4f3d18136fe2b5665c29bdaf74591fc6625ef427",
+      "amount": "21650000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147342,
+      "address": "0x175d728b0e0f1facb5822a2e0c03bde93596e324",
+      "amount": "21588000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147352,
+      "address": "0x8b635ef2e4c8fe21fc2bda027eb5f371d6aa2fc1",
+      "amount": "23115000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147357,
+      "address": "0x10f01a27cf9b29d02ce53497312b96037357a361",
+      "amount": "22716000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147363,
+      "address": "0x693dd49b0ed70f162d733cf20b6c43dc2a2b4d95",
+      "amount": "20020000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147369,
+      "address": "0xe0bec72d1c2a7a7fb0532cdfac44ebab9f6f41ee",
+      "amount": "23071000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147375,
+      "address": "0xc8793633a537938cb49cdbbffd45428f10e45b64",
+      "amount": "24652000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147380,
+      "address": "0x0d07a6cbbe9fa5c4f154e5623bfe47fb4d857d8e",
+      "amount": "21907000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147386,
+      "address": "0xd4080b289da95f70a586610c38268d8d4cf1e4c4",
+      "amount": "22719000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147392,
+      "address": "0x8bcfb0caf41f0aa1b548cae76dcdd02e33866a1b",
+      "amount": "29062000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147397,
+      "address": "0xabfef22b92366d3074676e77ea911ccaabfb64c1",
+      "amount": "23110000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147403,
+      "address": "0xcc4df7a32faf3efba32c9688def5ccf9fefe443d",
+      "amount": "21397000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147408,
+      "address": "0x7ec1e48a582475f5f2b7448a86c4ea7a26ea36f8",
+      "amount": "23105000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147414,
+      "address": "0xe3de67289080f63b0c2612844256a25bb99ac0ad",
+      "amount": "29721000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147420,
+      "address": "0x3ba623300cf9e48729039b3c9e0dee9b785d636e",
+      "amount": "25917000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147425,
+      "address": "0x402f2cfc9c8942f5e7a12c70c625d07a5d52fe29",
+      "amount": "24712000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147431,
+      "address": "0xd62358d42afbde095a4ca868581d85f9adcc3d61",
+      "amount": "24449000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147437,
+      "address": "0x3969f86acb733526cd61e3c6e3b4660589f32bc6",
+      "amount": "29579000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147442,
+      "address": "0x67615413d7cdadb2c435a946aec713a9a9794d39",
+      "amount": "26333000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147448,
+      "address": "0xfe685f43acc62f92ab01a8da80d76455d39d3cb3",
+      "amount": "29825000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147453,
+      "address": "0x3538a544021c07869c16b764424c5987409cba48",
+      "amount": "22746000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    },
+    {
+      "block": 9147459,
+      "address": "0xe187cf86c2274b1f16e8225a7da9a75aba4f1f5f",
+      "amount": "23734000000000000000000",
+      "reason": "balance restored by tomo for a blacklisted address before its transaction"
+    }
+  ]
+}
+`,
+}
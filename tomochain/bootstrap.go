@@ -7,6 +7,8 @@ import (
 	"github.com/spf13/cast"
 	"github.com/tomochain/tomochain-rosetta-gateway/common"
 	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/core"
+	"github.com/tomochain/tomochain/log"
 	"io/ioutil"
 	"math/big"
 	"strings"
@@ -27,7 +29,7 @@ func GenerateBootstrapFile(inputFile, outputFile string) error {
 	if err == nil {
 		err = json.Unmarshal(data, &genesis)
 		if err != nil {
-			fmt.Println("Error: ", err)
+			log.Error("unable to parse genesis file", "file", inputFile, "err", err)
 			return err
 		}
 	}
@@ -40,7 +42,7 @@ func GenerateBootstrapFile(inputFile, outputFile string) error {
 			if hexBalance, ok := walletData["balance"] ; ok {
 				balance, good := new(big.Int).SetString(strings.TrimPrefix(cast.ToString(hexBalance), "0x"), 16)
 				if !good {
-					fmt.Println("Cannot parse balance of address ", addr, err)
+					log.Error("unable to parse balance of address", "address", addr, "balance", hexBalance)
 					return err
 				}
 				if balance.Sign() <= 0 {
@@ -59,14 +61,36 @@ func GenerateBootstrapFile(inputFile, outputFile string) error {
 
 	output, err := json.MarshalIndent(bootstrapBalances, "", "	")
 	if err != nil {
-		fmt.Println("Unable to marshal bootstrapBalances", err)
+		log.Error("unable to marshal bootstrap balances", "err", err)
 		return err
 	}
 	err = ioutil.WriteFile(outputFile, output, 0644)
 
 	if err != nil {
-		fmt.Println("Unable to write output file", outputFile, err)
+		log.Error("unable to write bootstrap balances", "file", outputFile, "err", err)
 	}
 	return nil
 }
 
+// LoadGenesisFile parses a TomoChain genesis file.
+func LoadGenesisFile(genesisFile string) (*core.Genesis, error) {
+	data, err := ioutil.ReadFile(genesisFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read genesis file %s", err, genesisFile)
+	}
+
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(data, genesis); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse genesis file %s", err, genesisFile)
+	}
+	return genesis, nil
+}
+
+// GenesisBlockIdentifier computes the identifier
+// of the genesis block described by a genesis.
+func GenesisBlockIdentifier(genesis *core.Genesis) *RosettaTypes.BlockIdentifier {
+	return &RosettaTypes.BlockIdentifier{
+		Hash:  genesis.ToBlock(nil).Hash().Hex(),
+		Index: GenesisBlockIndex,
+	}
+}
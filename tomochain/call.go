@@ -0,0 +1,164 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"fmt"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"sort"
+	"sync"
+)
+
+const (
+	// types of /call parameters
+	CallParamString  = "string"
+	CallParamInteger = "integer"
+	CallParamBoolean = "boolean"
+	CallParamObject  = "object"
+)
+
+type (
+	// CallHandler executes a /call method against the node.
+	CallHandler func(ctx context.Context, tc *Client, parameters map[string]interface{}) (*RosettaTypes.CallResponse, error)
+
+	// CallParameter describes a parameter accepted by a /call method.
+	CallParameter struct {
+		Name     string
+		Type     string
+		Required bool
+	}
+
+	// CallMethod is a method served by the /call endpoint.
+	CallMethod struct {
+		Name       string
+		Parameters []*CallParameter
+		Handler    CallHandler
+	}
+
+	// CallRegistry holds the methods served by the /call endpoint.
+	CallRegistry interface {
+		// Register adds a method to the registry. It fails if a method
+		// with the same name is already registered.
+		Register(method *CallMethod) error
+
+		// Method returns the method registered with the given name.
+		Method(name string) (*CallMethod, bool)
+
+		// Names returns the sorted names of all registered methods.
+		Names() []string
+	}
+
+	callRegistry struct {
+		sync.RWMutex
+		methods map[string]*CallMethod
+	}
+)
+
+// DefaultCallRegistry is the registry used by the gateway. Forks can
+// register their own methods in it before the server starts.
+var DefaultCallRegistry = NewCallRegistry(
+	getTransactionReceiptMethod,
+	getEpochRewardsMethod,
+	getMasternodesMethod,
+	getCandidatesMethod,
+	getCandidateVotersMethod,
+)
+
+// NewCallRegistry returns a CallRegistry holding the given methods.
+// It panics if two methods share the same name.
+func NewCallRegistry(methods ...*CallMethod) CallRegistry {
+	r := &callRegistry{
+		methods: map[string]*CallMethod{},
+	}
+	for _, method := range methods {
+		if err := r.Register(method); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (r *callRegistry) Register(method *CallMethod) error {
+	if method == nil || len(method.Name) == 0 || method.Handler == nil {
+		return fmt.Errorf("call method must have a name and a handler")
+	}
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.methods[method.Name]; ok {
+		return fmt.Errorf("call method %s already registered", method.Name)
+	}
+	r.methods[method.Name] = method
+	return nil
+}
+
+func (r *callRegistry) Method(name string) (*CallMethod, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	method, ok := r.methods[name]
+	return method, ok
+}
+
+func (r *callRegistry) Names() []string {
+	r.RLock()
+	defer r.RUnlock()
+	names := make([]string, 0, len(r.methods))
+	for name := range r.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateParameters checks the parameters of a call request
+// against the parameter schema of the method.
+func (m *CallMethod) ValidateParameters(parameters map[string]interface{}) error {
+	for _, param := range m.Parameters {
+		value, ok := parameters[param.Name]
+		if !ok || value == nil {
+			if param.Required {
+				return fmt.Errorf("%w: %s missing from params", ErrCallParametersInvalid, param.Name)
+			}
+			continue
+		}
+		if !callParamTypeMatches(param.Type, value) {
+			return fmt.Errorf("%w: %s must be of type %s", ErrCallParametersInvalid, param.Name, param.Type)
+		}
+	}
+	return nil
+}
+
+func callParamTypeMatches(paramType string, value interface{}) bool {
+	switch paramType {
+	case CallParamString:
+		_, ok := value.(string)
+		return ok
+	case CallParamInteger:
+		// numbers decoded from JSON are float64
+		switch v := value.(type) {
+		case float64:
+			return v == float64(int64(v))
+		case int, int64, uint64:
+			return true
+		}
+		return false
+	case CallParamBoolean:
+		_, ok := value.(bool)
+		return ok
+	case CallParamObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+// callResponse marshals the output of a call method into a *RosettaTypes.CallResponse.
+func callResponse(output interface{}) (*RosettaTypes.CallResponse, error) {
+	result, err := RosettaTypes.MarshalMap(output)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+	return &RosettaTypes.CallResponse{
+		Result: result,
+	}, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+)
+
+var (
+	blockQueryParameters = []*CallParameter{
+		{Name: "index", Type: CallParamInteger},
+		{Name: "hash", Type: CallParamString},
+	}
+
+	getTransactionReceiptMethod = &CallMethod{
+		Name: common.RPC_METHOD_GET_TRANSACTION_RECEIPT,
+		Parameters: []*CallParameter{
+			{Name: "tx_hash", Type: CallParamString, Required: true},
+		},
+		Handler: getTransactionReceipt,
+	}
+
+	getEpochRewardsMethod = &CallMethod{
+		Name:       common.CALL_METHOD_GET_EPOCH_REWARDS,
+		Parameters: blockQueryParameters,
+		Handler:    getEpochRewards,
+	}
+
+	getMasternodesMethod = &CallMethod{
+		Name:       common.CALL_METHOD_GET_MASTERNODES,
+		Parameters: blockQueryParameters,
+		Handler:    getMasternodes,
+	}
+
+	getCandidatesMethod = &CallMethod{
+		Name:       common.CALL_METHOD_GET_CANDIDATES,
+		Parameters: blockQueryParameters,
+		Handler:    getCandidates,
+	}
+
+	getCandidateVotersMethod = &CallMethod{
+		Name: common.CALL_METHOD_GET_CANDIDATE_VOTERS,
+		Parameters: append([]*CallParameter{
+			{Name: "candidate", Type: CallParamString, Required: true},
+		}, blockQueryParameters...),
+		Handler: getCandidateVoters,
+	}
+)
+
+func getTransactionReceipt(
+	ctx context.Context,
+	tc *Client,
+	parameters map[string]interface{},
+) (*RosettaTypes.CallResponse, error) {
+	var input GetTransactionReceiptInput
+	if err := RosettaTypes.UnmarshalMap(parameters, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if len(input.TxHash) == 0 {
+		return nil, fmt.Errorf("%w:tx_hash missing from params", ErrCallParametersInvalid)
+	}
+
+	receipt, err := tc.transactionReceipt(ctx, tomochaincommon.HexToHash(input.TxHash))
+	if err != nil {
+		return nil, err
+	}
+
+	// We cannot use RosettaTypes.MarshalMap because geth uses a custom
+	// marshaler to convert *types.Receipt to JSON.
+	jsonOutput, err := receipt.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	var receiptMap map[string]interface{}
+	if err := json.Unmarshal(jsonOutput, &receiptMap); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	// We must encode data over the wire so we can unmarshal correctly
+	return &RosettaTypes.CallResponse{
+		Result: receiptMap,
+	}, nil
+}
+
+func getEpochRewards(
+	ctx context.Context,
+	tc *Client,
+	parameters map[string]interface{},
+) (*RosettaTypes.CallResponse, error) {
+	var input GetEpochRewardsInput
+	if err := RosettaTypes.UnmarshalMap(parameters, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	rewards, err := tc.epochRewards(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return callResponse(rewards)
+}
+
+func getMasternodes(
+	ctx context.Context,
+	tc *Client,
+	parameters map[string]interface{},
+) (*RosettaTypes.CallResponse, error) {
+	var input GetMasternodesInput
+	if err := RosettaTypes.UnmarshalMap(parameters, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	masternodes, err := tc.masternodes(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return callResponse(masternodes)
+}
+
+func getCandidates(
+	ctx context.Context,
+	tc *Client,
+	parameters map[string]interface{},
+) (*RosettaTypes.CallResponse, error) {
+	var input GetCandidatesInput
+	if err := RosettaTypes.UnmarshalMap(parameters, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	candidates, err := tc.candidates(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return callResponse(candidates)
+}
+
+func getCandidateVoters(
+	ctx context.Context,
+	tc *Client,
+	parameters map[string]interface{},
+) (*RosettaTypes.CallResponse, error) {
+	var input GetCandidateVotersInput
+	if err := RosettaTypes.UnmarshalMap(parameters, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	voters, err := tc.candidateVoters(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return callResponse(voters)
+}
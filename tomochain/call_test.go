@@ -0,0 +1,368 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain/accounts/abi"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/common/hexutil"
+	"github.com/tomochain/tomochain/contracts/validator/contract"
+)
+
+func TestValidateParameters(t *testing.T) {
+	method := &CallMethod{
+		Name: "test",
+		Parameters: []*CallParameter{
+			{Name: "name", Type: CallParamString, Required: true},
+			{Name: "index", Type: CallParamInteger},
+			{Name: "full", Type: CallParamBoolean},
+			{Name: "filter", Type: CallParamObject},
+			{Name: "any", Type: "any"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		parameters map[string]interface{}
+		valid      bool
+	}{
+		{name: "required only", parameters: map[string]interface{}{"name": "a"}, valid: true},
+		{
+			name: "all",
+			parameters: map[string]interface{}{
+				"name":   "a",
+				"index":  float64(3),
+				"full":   true,
+				"filter": map[string]interface{}{},
+				"any":    []interface{}{},
+			},
+			valid: true,
+		},
+		{name: "integer", parameters: map[string]interface{}{"name": "a", "index": 3}, valid: true},
+		{name: "unknown parameters", parameters: map[string]interface{}{"name": "a", "other": 1}, valid: true},
+		{name: "null optional", parameters: map[string]interface{}{"name": "a", "index": nil}, valid: true},
+		{name: "missing required", parameters: map[string]interface{}{"index": float64(3)}},
+		{name: "null required", parameters: map[string]interface{}{"name": nil}},
+		{name: "nil parameters"},
+		{name: "string as integer", parameters: map[string]interface{}{"name": "a", "index": "3"}},
+		{name: "fraction as integer", parameters: map[string]interface{}{"name": "a", "index": 1.5}},
+		{name: "integer as string", parameters: map[string]interface{}{"name": float64(1)}},
+		{name: "string as boolean", parameters: map[string]interface{}{"name": "a", "full": "true"}},
+		{name: "array as object", parameters: map[string]interface{}{"name": "a", "filter": []interface{}{}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := method.ValidateParameters(test.parameters)
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !test.valid && !errors.Is(err, ErrCallParametersInvalid) {
+				t.Fatalf("error %v, want %v", err, ErrCallParametersInvalid)
+			}
+		})
+	}
+}
+
+func TestCallRegistry(t *testing.T) {
+	handler := func(context.Context, *Client, map[string]interface{}) (*RosettaTypes.CallResponse, error) {
+		return nil, nil
+	}
+	registry := NewCallRegistry(&CallMethod{Name: "b", Handler: handler}, &CallMethod{Name: "a", Handler: handler})
+
+	if err := registry.Register(&CallMethod{Name: "a", Handler: handler}); err == nil {
+		t.Error("a method registered twice must fail")
+	}
+	if err := registry.Register(&CallMethod{Name: "c"}); err == nil {
+		t.Error("a method without handler must fail")
+	}
+	if names := registry.Names(); strings.Join(names, ",") != "a,b" {
+		t.Errorf("names %v, want [a b]", names)
+	}
+	if _, ok := registry.Method("c"); ok {
+		t.Error("method c is not registered")
+	}
+}
+
+var (
+	testMasternodes = []tomochaincommon.Address{
+		tomochaincommon.HexToAddress("0x0000000000000000000000000000000000000a01"),
+		tomochaincommon.HexToAddress("0x0000000000000000000000000000000000000a02"),
+	}
+	testSlashed   = tomochaincommon.HexToAddress("0x0000000000000000000000000000000000000a03")
+	testProposed  = tomochaincommon.HexToAddress("0x0000000000000000000000000000000000000a04")
+	testVoterCaps = map[tomochaincommon.Address]*big.Int{
+		tomochaincommon.HexToAddress(testVoter): big.NewInt(100),
+		tomochaincommon.HexToAddress(testOwner): big.NewInt(0),
+	}
+)
+
+// validatorTomo serves checkpoint blocks listing testMasternodes and
+// penalizing testSlashed, and the TomoValidator contract.
+func validatorTomo(t *testing.T) *fakeTomo {
+	var lookups int32
+	node := rewardTomo(t, &lookups)
+
+	header := func(number uint64) map[string]interface{} {
+		extra := make([]byte, common.ExtraVanity)
+		for _, masternode := range testMasternodes {
+			extra = append(extra, masternode.Bytes()...)
+		}
+		extra = append(extra, make([]byte, common.ExtraSeal)...)
+		return map[string]interface{}{
+			"hash":      fmt.Sprintf("0x%064x", number),
+			"number":    fmt.Sprintf("0x%x", number),
+			"timestamp": "0x5f5e1000",
+			"extraData": hexutil.Bytes(extra),
+			"penalties": hexutil.Bytes(testSlashed.Bytes()),
+		}
+	}
+	node.handle(common.RPC_METHOD_GET_BLOCK_BY_NUMBER, func(params []json.RawMessage) (interface{}, error) {
+		var arg string
+		if err := json.Unmarshal(params[0], &arg); err != nil {
+			return nil, err
+		}
+		if arg == "latest" {
+			return header(1800), nil
+		}
+		number, err := hexutil.DecodeUint64(arg)
+		if err != nil {
+			return nil, err
+		}
+		return header(number), nil
+	})
+	node.handle(common.RPC_METHOD_GET_BLOCK_BY_HASH, func(params []json.RawMessage) (interface{}, error) {
+		var hash tomochaincommon.Hash
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		return header(hash.Big().Uint64()), nil
+	})
+
+	validatorABI, err := abi.JSON(strings.NewReader(contract.TomoValidatorABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var msg struct {
+			Data hexutil.Bytes `json:"data"`
+		}
+		if err := json.Unmarshal(params[0], &msg); err != nil {
+			return nil, err
+		}
+		method, err := validatorABI.MethodById(msg.Data[:4])
+		if err != nil {
+			return nil, err
+		}
+		args, err := method.Inputs.UnpackValues(msg.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+
+		var result interface{}
+		switch method.Name {
+		case "getCandidates":
+			result = append(append([]tomochaincommon.Address{}, testMasternodes...), testSlashed, testProposed, tomochaincommon.Address{})
+		case "getCandidateCap":
+			result = new(big.Int).SetBytes(args[0].(tomochaincommon.Address).Bytes())
+		case "getCandidateOwner":
+			result = tomochaincommon.HexToAddress(testOwner)
+		case "isCandidate":
+			result = args[0].(tomochaincommon.Address) != tomochaincommon.HexToAddress(testVoter)
+		case "getVoters":
+			result = []tomochaincommon.Address{tomochaincommon.HexToAddress(testVoter), tomochaincommon.HexToAddress(testOwner), tomochaincommon.HexToAddress(testVoter)}
+		case "getVoterCap":
+			result = testVoterCaps[args[1].(tomochaincommon.Address)]
+		default:
+			return nil, fmt.Errorf("unexpected call of %s", method.Name)
+		}
+		output, err := method.Outputs.Pack(result)
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.Bytes(output), nil
+	})
+
+	node.handle(common.RPC_METHOD_GET_TRANSACTION_RECEIPT, func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"status":            "0x1",
+			"cumulativeGasUsed": "0x5208",
+			"gasUsed":           "0x5208",
+			"logsBloom":         hexutil.Bytes(make([]byte, 256)),
+			"logs":              []interface{}{},
+			"transactionHash":   params[0],
+			"contractAddress":   nil,
+		}, nil
+	})
+	return node
+}
+
+// keys returns the sorted keys of a call result.
+func keys(result map[string]interface{}) string {
+	names := make([]string, 0, len(result))
+	for name := range result {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func TestCallHandlers(t *testing.T) {
+	node := validatorTomo(t)
+	defer node.Close()
+	client := newTestClient(t, node)
+	defer client.Close()
+
+	owner, _ := Checksum(testOwner)
+	voter, _ := Checksum(testVoter)
+	tests := []struct {
+		method     string
+		parameters map[string]interface{}
+		keys       string
+		check      func(t *testing.T, result map[string]interface{})
+		err        error
+	}{
+		{
+			method:     common.RPC_METHOD_GET_TRANSACTION_RECEIPT,
+			parameters: map[string]interface{}{"tx_hash": fmt.Sprintf("0x%064x", 1)},
+			keys:       "contractAddress,cumulativeGasUsed,gasUsed,logs,logsBloom,root,status,transactionHash",
+		},
+		{
+			method: common.RPC_METHOD_GET_TRANSACTION_RECEIPT,
+			err:    ErrCallParametersInvalid,
+		},
+		{
+			method:     common.CALL_METHOD_GET_EPOCH_REWARDS,
+			parameters: map[string]interface{}{"index": float64(1800)},
+			keys:       "block_identifier,epoch,rewards,signers,totals",
+			check: func(t *testing.T, result map[string]interface{}) {
+				totals := result["totals"].(map[string]interface{})
+				if keys(totals) != "foundation,owner,total,voter" {
+					t.Errorf("totals %v", totals)
+				}
+			},
+		},
+		{
+			method:     common.CALL_METHOD_GET_EPOCH_REWARDS,
+			parameters: map[string]interface{}{"index": float64(1801)},
+			err:        ErrCallParametersInvalid,
+		},
+		{
+			method:     common.CALL_METHOD_GET_MASTERNODES,
+			parameters: map[string]interface{}{"hash": fmt.Sprintf("0x%064x", 1805)},
+			keys:       "block_identifier,checkpoint_identifier,epoch,masternodes",
+			check: func(t *testing.T, result map[string]interface{}) {
+				checkpoint := result["checkpoint_identifier"].(map[string]interface{})
+				if checkpoint["index"] != float64(1800) || result["epoch"] != float64(2) {
+					t.Errorf("checkpoint %v of epoch %v, want 1800 of epoch 2", checkpoint["index"], result["epoch"])
+				}
+				if masternodes := result["masternodes"].([]interface{}); len(masternodes) != len(testMasternodes) {
+					t.Errorf("masternodes %v", masternodes)
+				}
+			},
+		},
+		{
+			method: common.CALL_METHOD_GET_MASTERNODES,
+			err:    ErrCallParametersInvalid,
+		},
+		{
+			method:     common.CALL_METHOD_GET_CANDIDATES,
+			parameters: map[string]interface{}{"index": float64(1805)},
+			keys:       "block_identifier,candidates,checkpoint_identifier,epoch",
+			check: func(t *testing.T, result map[string]interface{}) {
+				// candidates are sorted by capacity, which is their address here
+				want := []string{common.CANDIDATE_STATUS_PROPOSED, common.CANDIDATE_STATUS_SLASHED, common.CANDIDATE_STATUS_MASTERNODE, common.CANDIDATE_STATUS_MASTERNODE}
+				candidates := result["candidates"].([]interface{})
+				if len(candidates) != len(want) {
+					t.Fatalf("%d candidates, want %d", len(candidates), len(want))
+				}
+				for i, c := range candidates {
+					candidate := c.(map[string]interface{})
+					if keys(candidate) != "address,capacity,owner,status" {
+						t.Errorf("candidate %v", candidate)
+					}
+					if candidate["status"] != want[i] {
+						t.Errorf("candidate %s is %s, want %s", candidate["address"], candidate["status"], want[i])
+					}
+				}
+			},
+		},
+		{
+			method:     common.CALL_METHOD_GET_CANDIDATE_VOTERS,
+			parameters: map[string]interface{}{"candidate": testMasternodes[0].Hex(), "index": float64(1805)},
+			keys:       "block_identifier,candidate,capacity,owner,voters",
+			check: func(t *testing.T, result map[string]interface{}) {
+				if result["owner"] != owner {
+					t.Errorf("owner %v, want %s", result["owner"], owner)
+				}
+				// voters are listed once, without their withdrawn stakes
+				voters := result["voters"].([]interface{})
+				if len(voters) != 1 {
+					t.Fatalf("voters %v, want only %s", voters, voter)
+				}
+				if v := voters[0].(map[string]interface{}); v["address"] != voter || v["capacity"] != "100" {
+					t.Errorf("voter %v, want %s with 100", v, voter)
+				}
+			},
+		},
+		{
+			method:     common.CALL_METHOD_GET_CANDIDATE_VOTERS,
+			parameters: map[string]interface{}{"candidate": testVoter, "index": float64(1805)},
+			err:        ErrCallParametersInvalid,
+		},
+		{
+			method:     common.CALL_METHOD_GET_CANDIDATE_VOTERS,
+			parameters: map[string]interface{}{"candidate": "0x1", "index": float64(1805)},
+			err:        ErrCallParametersInvalid,
+		},
+		{
+			method: "tomo_unknown",
+			err:    ErrCallMethodInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			response, err := client.Call(context.Background(), &RosettaTypes.CallRequest{
+				Method:     test.method,
+				Parameters: test.parameters,
+			})
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("error %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// results are served as JSON
+			data, err := json.Marshal(response.Result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result map[string]interface{}
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatal(err)
+			}
+			if keys(result) != test.keys {
+				t.Fatalf("result keys %s, want %s", keys(result), test.keys)
+			}
+			if test.check != nil {
+				test.check(t, result)
+			}
+		})
+	}
+}
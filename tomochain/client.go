@@ -20,9 +20,7 @@ import (
 	"github.com/tomochain/tomochain/params"
 	"github.com/tomochain/tomochain/rpc"
 	"golang.org/x/sync/semaphore"
-	"log"
 	"math/big"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -39,36 +37,135 @@ type (
 	// Client is an implementation of TomoChain client using local rpc/ipc connection.
 	Client struct {
 		sync.RWMutex
+		tracer         *Tracer
 		tracerConfig   *eth.TraceConfig
 		traceSemaphore *semaphore.Weighted
-		c              *rpc.Client
+		c              *upstreamPool
 		p              *params.ChainConfig
+		rules          *ChainRules
+		tip            *TipPolicy
+		syncStaleness  time.Duration
+		specialRewards map[uint64]*specialRewardEntry
+		calls          CallRegistry
+		owners         *ownerCache
+
+		// storesRewards is set once tomo is known to store epoch rewards
+		storesRewards bool
+
+		includeZeroValueCalls bool
 	}
+
+	zeroValueCallsKey struct{}
 )
 
+// ClientOptions are the optional settings of a Client.
+type ClientOptions struct {
+	// TipPolicy decides which block is reported as the tip,
+	// DefaultTipPolicy is used if it is nil.
+	TipPolicy *TipPolicy
+
+	// SyncStaleness is the age of the latest block above which the
+	// node is not considered synced. Zero disables the check.
+	SyncStaleness time.Duration
+
+	// HTTPTimeout is the timeout of a request to tomo,
+	// tomoHTTPTimeout is used if it is zero.
+	HTTPTimeout time.Duration
+
+	// MaxTraceConcurrency is the maximum number of transactions traced
+	// at the same time, maxTraceConcurrency is used if it is zero.
+	MaxTraceConcurrency int64
+
+	// Tracer is the call tracer run by tomo, the
+	// bundled tracer is used if it is nil.
+	Tracer *Tracer
+
+	// TracerTimeout is the timeout of the call tracer for a single
+	// transaction, defaultTracerTimeout is used if it is zero.
+	TracerTimeout time.Duration
+
+	// IncludeZeroValueCalls adds operations without amount for the
+	// calls which transfer no value, see WithZeroValueCalls.
+	IncludeZeroValueCalls bool
+}
+
 // cache chainId to avoid spam rpc
 var chainId *big.Int
 
-func NewClient(url string, params *params.ChainConfig) (cli *Client, err error) {
-	rpcClient, err := rpc.DialHTTPWithClient(url, &http.Client{
-		Timeout: tomoHTTPTimeout,
-	})
-	if err != nil {
-		return nil, err
+// NewClient creates a client sending requests to the given tomo nodes,
+// see upstreamPool for how requests are spread over the nodes.
+func NewClient(urls []string, rules *ChainRules, opts *ClientOptions) (cli *Client, err error) {
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: invalid chain rules", err)
+	}
+	if opts == nil {
+		opts = &ClientOptions{}
+	}
+	tip := opts.TipPolicy
+	if tip == nil {
+		tip = DefaultTipPolicy
+	}
+	if err := tip.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: invalid tip policy", err)
+	}
+
+	httpTimeout := opts.HTTPTimeout
+	if httpTimeout == 0 {
+		httpTimeout = tomoHTTPTimeout
+	}
+	traceConcurrency := opts.MaxTraceConcurrency
+	if traceConcurrency == 0 {
+		traceConcurrency = maxTraceConcurrency
+	}
+	tracerTimeout := opts.TracerTimeout
+	if tracerTimeout == 0 {
+		tracerTimeout = defaultTracerTimeout
+	}
+
+	tracer := opts.Tracer
+	if tracer == nil {
+		if tracer, err = LoadTracer(""); err != nil {
+			return nil, err
+		}
 	}
-	tracerConfig, err := loadTraceConfig()
+	pool, err := newUpstreamPool(urls, httpTimeout, rules.Params.ChainId)
 	if err != nil {
-		return nil, fmt.Errorf("%w: unable to load trace config", err)
+		return nil, err
 	}
 	return &Client{
-		c:              rpcClient,
-		tracerConfig:   tracerConfig,
-		traceSemaphore: semaphore.NewWeighted(maxTraceConcurrency),
-		p:              params,
+		c:              pool,
+		tracer:         tracer,
+		tracerConfig:   loadTraceConfig(tracer, tracerTimeout),
+		traceSemaphore: semaphore.NewWeighted(traceConcurrency),
+		p:              rules.Params,
+		rules:          rules,
+		tip:            tip,
+		syncStaleness:  opts.SyncStaleness,
+		specialRewards: rules.specialRewardsByBlock(),
+		calls:          DefaultCallRegistry,
+		owners:         newOwnerCache(),
+
+		includeZeroValueCalls: opts.IncludeZeroValueCalls,
 	}, nil
 }
 
-// Close shuts down the RPC client connection.
+// WithZeroValueCalls returns a copy of ctx overriding, for the blocks
+// parsed with it, whether the calls which transfer no value are
+// turned into operations.
+func WithZeroValueCalls(ctx context.Context, include bool) context.Context {
+	return context.WithValue(ctx, zeroValueCallsKey{}, include)
+}
+
+// zeroValueCalls reports whether the calls which transfer
+// no value are turned into operations for ctx.
+func (tc *Client) zeroValueCalls(ctx context.Context) bool {
+	if include, ok := ctx.Value(zeroValueCallsKey{}).(bool); ok {
+		return include
+	}
+	return tc.includeZeroValueCalls
+}
+
+// Close shuts down the RPC client connections.
 func (tc *Client) Close() {
 	tc.c.Close()
 }
@@ -100,10 +197,33 @@ func (tc *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	return new(big.Int).SetUint64(uint64(result)), nil
 }
 
-// Peers retrieves all peers of the node.
-func (tc *Client) peers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
+// Peers retrieves all peers of the node and the source they come from.
+// admin_peers is usually not exposed by remote nodes, peers are then
+// made up from net_peerCount. If it is not exposed either, no peer is
+// returned from source "none". The source is exported as a metric
+// because the response has no metadata telling it when there is no peer.
+func (tc *Client) peers(ctx context.Context) ([]*RosettaTypes.Peer, string) {
+	peers, err := tc.adminPeers(ctx)
+	if err == nil {
+		observePeers(common.RPC_METHOD_ADMIN_PEERS, len(peers))
+		return peers, common.RPC_METHOD_ADMIN_PEERS
+	}
+
+	peers, countErr := tc.countedPeers(ctx)
+	if countErr == nil {
+		observePeers(common.RPC_METHOD_NET_PEER_COUNT, len(peers))
+		return peers, common.RPC_METHOD_NET_PEER_COUNT
+	}
+
+	common.Logger(ctx).Warn("unable to get peers", "err", err, "count_err", countErr, "source", common.PEER_SOURCE_NONE)
+	observePeers(common.PEER_SOURCE_NONE, 0)
+	return []*RosettaTypes.Peer{}, common.PEER_SOURCE_NONE
+}
+
+// adminPeers retrieves all peers of the node using the admin module.
+func (tc *Client) adminPeers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
 	var info []*p2p.PeerInfo
-	if err := tc.c.CallContext(ctx, &info, "admin_peers"); err != nil {
+	if err := tc.c.CallContext(ctx, &info, common.RPC_METHOD_ADMIN_PEERS); err != nil {
 		return nil, err
 	}
 
@@ -112,9 +232,31 @@ func (tc *Client) peers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
 		peers[i] = &RosettaTypes.Peer{
 			PeerID: peerInfo.ID,
 			Metadata: map[string]interface{}{
-				"name":      peerInfo.Name,
-				"caps":      peerInfo.Caps,
-				"protocols": peerInfo.Protocols,
+				"name":                      peerInfo.Name,
+				"caps":                      peerInfo.Caps,
+				"protocols":                 peerInfo.Protocols,
+				common.METADATA_PEER_SOURCE: common.RPC_METHOD_ADMIN_PEERS,
+			},
+		}
+	}
+
+	return peers, nil
+}
+
+// countedPeers returns a synthetic peer for each peer counted by the node
+// because net_peerCount does not identify them.
+func (tc *Client) countedPeers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
+	var count hexutil.Uint64
+	if err := tc.c.CallContext(ctx, &count, common.RPC_METHOD_NET_PEER_COUNT); err != nil {
+		return nil, err
+	}
+
+	peers := make([]*RosettaTypes.Peer, count)
+	for i := range peers {
+		peers[i] = &RosettaTypes.Peer{
+			PeerID: fmt.Sprintf("peer-%d", i),
+			Metadata: map[string]interface{}{
+				common.METADATA_PEER_SOURCE: common.RPC_METHOD_NET_PEER_COUNT,
 			},
 		}
 	}
@@ -136,11 +278,12 @@ func (tc *Client) GetChainID(ctx context.Context) (*big.Int, error) {
 
 // Block returns a populated block at the *RosettaTypes.PartialBlockIdentifier.
 // If neither the hash or index is populated in the *RosettaTypes.PartialBlockIdentifier,
-// the current block is returned.
+// the tip of the chain under the tip policy is returned.
 func (tc *Client) Block(
 	ctx context.Context,
 	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
 ) (*RosettaTypes.Block, error) {
+	ctx = tc.c.pin(ctx)
 	if blockIdentifier != nil {
 		if blockIdentifier.Hash != nil {
 			return tc.getParsedBlock(ctx, common.RPC_METHOD_GET_BLOCK_BY_HASH, *blockIdentifier.Hash, true)
@@ -156,7 +299,19 @@ func (tc *Client) Block(
 		}
 	}
 
-	return tc.getParsedBlock(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(nil), true)
+	if tc.tip.Mode == TipLatest {
+		return tc.getParsedBlock(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(nil), true)
+	}
+
+	latest, posvLatest, err := tc.blockHeader(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get latest header", err)
+	}
+	header, _, err := tc.tipHeader(ctx, latest, posvLatest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get tip", err)
+	}
+	return tc.getParsedBlock(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(header.Number), true)
 }
 
 func (tc *Client) getUncles(
@@ -237,7 +392,7 @@ func (tc *Client) getBlock(
 
 	var data map[string]interface{}
 	if err = json.Unmarshal(raw, &data); err != nil {
-		fmt.Println("getBlockByNumber error when unmarshalling raw data")
+		common.Logger(ctx).Error("unable to decode block", "method", blockMethod, "err", err)
 		return nil, []*loadedTransaction{}, "", err
 	}
 	// include M2 signature
@@ -283,7 +438,7 @@ func (tc *Client) getBlock(
 		addTraces = true
 		miner, err = GetCoinbaseFromHeader(&head)
 		if err != nil {
-			fmt.Println("Failed to get miner of block", head.Number, finalBlockHash)
+			common.Logger(ctx).Error("unable to get miner of block", "number", head.Number, "err", err)
 			return nil, nil, "", err
 		}
 	}
@@ -302,16 +457,17 @@ func (tc *Client) getBlock(
 		loadedTxs[i].FeeAmount = feeAmount
 
 		// tx fee send to masternode owner since hardford common/common.go:22
-		if head.Number.Cmp(common.HardForkUpdateTxFee) < 0 {
-			loadedTxs[i].Miner = MustChecksum(miner.Hex())
-		} else {
-			var owner string
-			err := tc.c.CallContext(ctx, &owner, common.RPC_METHOD_GET_OWNER_BY_COINBASE, miner, toBlockNumArg(head.Number))
+		feeRecipient := miner.Hex()
+		if head.Number.Cmp(tc.rules.FeeHardForkBlock) >= 0 {
+			feeRecipient, err = tc.getOwnerByCoinbase(ctx, miner, head.Number)
 			if err != nil {
-				fmt.Println("Failed to get masternode owner of coinbase", head.Number, miner)
+				common.Logger(ctx).Error("unable to get masternode owner of coinbase", "number", head.Number, "coinbase", miner, "err", err)
 				return nil, nil, "", err
 			}
-			loadedTxs[i].Miner = MustChecksum(owner)
+		}
+		loadedTxs[i].Miner, err = Checksum(feeRecipient)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("%w: invalid fee recipient of block %d", err, head.Number)
 		}
 
 		loadedTxs[i].Receipt = receipt
@@ -331,19 +487,36 @@ func (tc *Client) getBlock(
 	return tomochaintypes.NewBlockWithHeader(&head).WithBody(txs, uncles), loadedTxs, finalBlockHash, nil
 }
 
+// acquireTrace waits for the trace semaphore, the returned function releases it.
+func (tc *Client) acquireTrace(ctx context.Context) (func(), error) {
+	start := time.Now()
+	err := tc.traceSemaphore.Acquire(ctx, semaphoreTraceWeight)
+	traceWait.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	tracesInFlight.Inc()
+	return func() {
+		tracesInFlight.Dec()
+		tc.traceSemaphore.Release(semaphoreTraceWeight)
+	}, nil
+}
+
 func (tc *Client) getBlockTraces(
 	ctx context.Context,
 	blockHash tomochaincommon.Hash,
 ) ([]*rpcCall, []*rpcRawCall, error) {
-	if err := tc.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+	release, err := tc.acquireTrace(ctx)
+	if err != nil {
 		return nil, nil, err
 	}
-	defer tc.traceSemaphore.Release(semaphoreTraceWeight)
+	defer release()
 
 	var calls []*rpcCall
 	var rawCalls []*rpcRawCall
 	var raw json.RawMessage
-	err := tc.c.CallContext(ctx, &raw, common.RPC_METHOD_DEBUG_TRACE_BLOCK, blockHash, tc.tracerConfig)
+	err = tc.c.CallContext(ctx, &raw, common.RPC_METHOD_DEBUG_TRACE_BLOCK, blockHash, tc.tracerConfig)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -365,15 +538,16 @@ func (tc *Client) getTransactionTraces(
 	ctx context.Context,
 	txHash tomochaincommon.Hash,
 ) (*Call, json.RawMessage, error) {
-	if err := tc.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+	release, err := tc.acquireTrace(ctx)
+	if err != nil {
 		return nil, nil, err
 	}
-	defer tc.traceSemaphore.Release(semaphoreTraceWeight)
+	defer release()
 
 	var calls *Call
 	var rawCalls json.RawMessage
 	var raw json.RawMessage
-	err := tc.c.CallContext(ctx, &raw, common.RPC_METHOD_DEBUG_TRACE_TRANSACTION, txHash, tc.tracerConfig)
+	err = tc.c.CallContext(ctx, &raw, common.RPC_METHOD_DEBUG_TRACE_TRANSACTION, txHash, tc.tracerConfig)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -393,14 +567,28 @@ func (tc *Client) getTransactionTraces(
 
 // Header returns a block header from the current canonical chain. If number is
 // nil, the latest known header is returned.
-func (tc *Client) blockHeader(ctx context.Context, number *big.Int) (*tomochaintypes.Header, error) {
-	var head *tomochaintypes.Header
-	err := tc.c.CallContext(ctx, &head, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(number), false)
-	if err == nil && head == nil {
-		return nil, tomochain.NotFound
+// The PoSV fields of the block are returned along with the header because
+// header.Hash() does not include the M2 signature of double validation.
+func (tc *Client) blockHeader(ctx context.Context, number *big.Int) (*tomochaintypes.Header, *rpcPosvHeader, error) {
+	var raw json.RawMessage
+	err := tc.c.CallContext(ctx, &raw, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(number), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, tomochain.NotFound
 	}
 
-	return head, err
+	var head tomochaintypes.Header
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, nil, err
+	}
+	var posvHead rpcPosvHeader
+	if err := json.Unmarshal(raw, &posvHead); err != nil {
+		return nil, nil, err
+	}
+
+	return &head, &posvHead, nil
 }
 
 func (tc *Client) GetLatestBlock(ctx context.Context) (*RosettaTypes.Block, error) {
@@ -487,11 +675,17 @@ func flattenTraces(data *Call, flattened []*flatCall) []*flatCall {
 }
 
 // traceOps returns all *RosettaTypes.Operation for a given
-// array of flattened traces.
-func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { // nolint: gocognit
+// array of flattened traces. The calls which transfer no value
+// are only turned into operations, without amount, if
+// includeZeroValueCalls is set.
+func traceOps( // nolint: gocognit
+	calls []*flatCall,
+	startIndex int,
+	includeZeroValueCalls bool,
+) ([]*RosettaTypes.Operation, error) {
 	var ops []*RosettaTypes.Operation
 	if len(calls) == 0 {
-		return ops
+		return ops, nil
 	}
 
 	destroyedAccounts := map[string]*big.Int{}
@@ -509,18 +703,24 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 			zeroValue = true
 		}
 
-		// Skip all 0 value CallType operations (TODO: make optional to include)
+		// Skip all 0 value CallType operations unless they are included
 		//
 		// We can't continue here because we may need to adjust our destroyed
 		// accounts map if a CallTYpe operation resurrects an account.
 		shouldAdd := true
-		if zeroValue && CallType(trace.Type) {
+		if zeroValue && CallType(trace.Type) && !includeZeroValueCalls {
 			shouldAdd = false
 		}
 
 		// Checksum addresses
-		from := MustChecksum(trace.From.String())
-		to := MustChecksum(trace.To.String())
+		from, err := Checksum(trace.From.String())
+		if err != nil {
+			return nil, err
+		}
+		to, err := Checksum(trace.To.String())
+		if err != nil {
+			return nil, err
+		}
 
 		if shouldAdd {
 			fromOp := &RosettaTypes.Operation{
@@ -619,7 +819,7 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 		}
 
 		if val.Sign() < 0 {
-			log.Fatalf("negative balance for suicided account %s: %s\n", acct, val.String())
+			return nil, fmt.Errorf("%w: %s has a balance of %s", ErrNegativeBalance, acct, val.String())
 		}
 
 		ops = append(ops, &RosettaTypes.Operation{
@@ -638,10 +838,19 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 		})
 	}
 
-	return ops
+	return ops, nil
 }
 
-func feeOps(tx *loadedTransaction) []*RosettaTypes.Operation {
+func feeOps(tx *loadedTransaction) ([]*RosettaTypes.Operation, error) {
+	from, err := Checksum(tx.From.String())
+	if err != nil {
+		return nil, err
+	}
+	miner, err := Checksum(tx.Miner)
+	if err != nil {
+		return nil, err
+	}
+
 	return []*RosettaTypes.Operation{
 		{
 			OperationIdentifier: &RosettaTypes.OperationIdentifier{
@@ -650,7 +859,7 @@ func feeOps(tx *loadedTransaction) []*RosettaTypes.Operation {
 			Type:   common.FeeOpType,
 			Status: &common.SUCCESS,
 			Account: &RosettaTypes.AccountIdentifier{
-				Address: MustChecksum(tx.From.String()),
+				Address: from,
 			},
 			Amount: &RosettaTypes.Amount{
 				Value:    new(big.Int).Neg(tx.FeeAmount).String(),
@@ -670,14 +879,14 @@ func feeOps(tx *loadedTransaction) []*RosettaTypes.Operation {
 			Type:   common.FeeOpType,
 			Status: &common.SUCCESS,
 			Account: &RosettaTypes.AccountIdentifier{
-				Address: MustChecksum(tx.Miner),
+				Address: miner,
 			},
 			Amount: &RosettaTypes.Amount{
 				Value:    tx.FeeAmount.String(),
 				Currency: common.TomoNativeCoin,
 			},
 		},
-	}
+	}, nil
 }
 
 // transactionReceipt returns the receipt of a transaction by transaction hash.
@@ -697,6 +906,54 @@ func (tc *Client) transactionReceipt(
 	return r, err
 }
 
+// Transaction returns a transaction of a block, the reward
+// transaction of a checkpoint block included.
+func (tc *Client) Transaction(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	transactionIdentifier *RosettaTypes.TransactionIdentifier,
+) (*RosettaTypes.Transaction, error) {
+	ctx = tc.c.pin(ctx)
+	if strings.EqualFold(transactionIdentifier.Hash, RewardTransactionHash(blockIdentifier.Hash)) {
+		// the rewards are read without parsing the whole block
+		head, err := tc.getPosvHeader(
+			ctx,
+			common.RPC_METHOD_GET_BLOCK_BY_HASH,
+			tomochaincommon.HexToHash(blockIdentifier.Hash),
+		)
+		if err != nil {
+			return nil, err
+		}
+		number := head.Number.ToInt().Int64()
+		if number != blockIdentifier.Index || number == 0 || uint64(number)%tc.rules.Epoch() != 0 {
+			return nil, fmt.Errorf("%w: block %d is not a checkpoint block", ErrTransactionNotFound, number)
+		}
+		return tc.populateRewardTransaction(ctx, &RosettaTypes.BlockIdentifier{
+			Hash:  head.Hash.Hex(),
+			Index: number,
+		})
+	}
+
+	block, err := tc.Block(ctx, &RosettaTypes.PartialBlockIdentifier{Hash: &blockIdentifier.Hash})
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockIdentifier.Index != blockIdentifier.Index {
+		return nil, fmt.Errorf(
+			"%w: block %s is at index %d",
+			ErrTransactionNotFound,
+			blockIdentifier.Hash,
+			block.BlockIdentifier.Index,
+		)
+	}
+	for _, tx := range block.Transactions {
+		if strings.EqualFold(tx.TransactionIdentifier.Hash, transactionIdentifier.Hash) {
+			return tx, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s in block %d", ErrTransactionNotFound, transactionIdentifier.Hash, block.BlockIdentifier.Index)
+}
+
 func (tc *Client) getParsedBlock(
 	ctx context.Context,
 	blockMethod string,
@@ -705,6 +962,7 @@ func (tc *Client) getParsedBlock(
 	*RosettaTypes.Block,
 	error,
 ) {
+	start := time.Now()
 	block, loadedTransactions, finalBlockHash, err := tc.getBlock(ctx, blockMethod, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not get block", err)
@@ -736,6 +994,13 @@ func (tc *Client) getParsedBlock(
 		return nil, err
 	}
 
+	operations := 0
+	for _, tx := range txs {
+		operations += len(tx.Operations)
+	}
+	blockParseDuration.Observe(time.Since(start).Seconds())
+	blockOperations.Observe(float64(operations))
+
 	return &RosettaTypes.Block{
 		BlockIdentifier:       blockIdentifier,
 		ParentBlockIdentifier: parentBlockIdentifier,
@@ -760,13 +1025,14 @@ func (tc *Client) populateTransactions(
 		err          error
 	)
 	// Compute reward transaction (block + uncle reward)
-	if block.NumberU64()%common.Epoch == 0 && block.NumberU64() > 0 {
+	if block.NumberU64()%tc.rules.Epoch() == 0 && block.NumberU64() > 0 {
 		rewardTx, err = tc.populateRewardTransaction(ctx, blockIdentifier)
 		if err != nil {
-			return []*RosettaTypes.Transaction{}, nil
+			return nil, fmt.Errorf("%w: unable to get rewards of checkpoint block %d", err, blockIdentifier.Index)
 		}
 	}
 
+	includeZeroValueCalls := tc.zeroValueCalls(ctx)
 	index := uint64(0)
 	if rewardTx != nil {
 		transactions = make(
@@ -785,6 +1051,7 @@ func (tc *Client) populateTransactions(
 	for _, tx := range loadedTransactions {
 		transaction, err := tc.populateTransaction(
 			tx,
+			includeZeroValueCalls,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%w: cannot parse %s", err, tx.Transaction.Hash().Hex())
@@ -802,53 +1069,39 @@ func (tc *Client) populateRewardTransaction(
 	ctx context.Context,
 	blockIdentifier *RosettaTypes.BlockIdentifier,
 ) (*RosettaTypes.Transaction, error) {
-	rewards, err := tc.GetBlockReward(ctx, tomochaincommon.HexToHash(blockIdentifier.Hash))
+	rewardOperations, err := tc.rewardOps(ctx, blockIdentifier)
 	if err != nil {
 		return nil, err
 	}
-	rewardOperations := []*RosettaTypes.Operation{}
-	if rewards != nil {
-		for _, signer := range rewards {
-			for holder, amount := range signer {
-				rewardOperations = append(rewardOperations, &RosettaTypes.Operation{
-					OperationIdentifier: &RosettaTypes.OperationIdentifier{
-						Index: int64(len(rewardOperations)),
-					},
-					RelatedOperations: nil,
-					Type:              common.MinerRewardOpType,
-					Status:            &common.SUCCESS,
-					Account: &RosettaTypes.AccountIdentifier{
-						Address: MustChecksum(holder),
-					},
-					Amount: &RosettaTypes.Amount{
-						Value:    amount.String(),
-						Currency: common.TomoNativeCoin,
-					},
-				})
-			}
-		}
-
-	}
 	return &RosettaTypes.Transaction{
-		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: blockIdentifier.Hash},
-		Operations:            rewardOperations,
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: RewardTransactionHash(blockIdentifier.Hash),
+		},
+		Operations: rewardOperations,
 	}, nil
 
 }
 
 func (tc *Client) populateTransaction(
 	tx *loadedTransaction,
+	includeZeroValueCalls bool,
 ) (*RosettaTypes.Transaction, error) {
 	ops := []*RosettaTypes.Operation{}
 
 	// Compute fee operations
-	feeOps := feeOps(tx)
+	feeOps, err := feeOps(tx)
+	if err != nil {
+		return nil, err
+	}
 	ops = append(ops, feeOps...)
 
 	// Compute trace operations
 	traces := flattenTraces(tx.Trace, []*flatCall{})
 
-	traceOps := traceOps(traces, len(ops))
+	traceOps, err := traceOps(traces, len(ops), includeZeroValueCalls)
+	if err != nil {
+		return nil, err
+	}
 	ops = append(ops, traceOps...)
 
 	// Marshal receipt and trace data
@@ -869,11 +1122,9 @@ func (tc *Client) populateTransaction(
 	}
 	specialReward := new(big.Int)
 	addrFrom := (*tx.From).Hex()
-	if addr, ok := common.SpecialRewardBlockMap[cast.ToUint64(*tx.BlockNumber)]; ok {
-		if strings.ToLower(addr) == strings.ToLower(addrFrom) {
-			bal := common.SpecialRewardAddrMap[addr]
-
-			specialReward.SetString(bal+"000000000000000000", 10)
+	if reward, ok := tc.specialRewards[cast.ToUint64(*tx.BlockNumber)]; ok {
+		if reward.address == strings.ToLower(addrFrom) {
+			specialReward.Set(reward.amount)
 		}
 	}
 	if specialReward.Sign() > 0 {
@@ -884,7 +1135,7 @@ func (tc *Client) populateTransaction(
 			Type:   common.MinerRewardOpType,
 			Status: &common.SUCCESS,
 			Account: &RosettaTypes.AccountIdentifier{
-				Address: MustChecksum(addrFrom),
+				Address: addrFrom,
 			},
 			Amount: &RosettaTypes.Amount{
 				Value:    specialReward.String(),
@@ -923,6 +1174,7 @@ func (tc *Client) EstimateGas(ctx context.Context, msg common.CallArgs) (uint64,
 // by block hash nor return the block hash where
 // the balance was fetched).
 func (tc *Client) Balance(ctx context.Context, account *RosettaTypes.AccountIdentifier, blockIdentifier *RosettaTypes.PartialBlockIdentifier) (res *RosettaTypes.AccountBalanceResponse, err error) {
+	ctx = tc.c.pin(ctx)
 	block, err := tc.Block(ctx, blockIdentifier)
 	if err != nil {
 		return nil, err
@@ -967,9 +1219,10 @@ func (tc *Client) GetBlockTransactions(ctx context.Context, hash tomochaincommon
 	return block.Transactions, nil
 }
 
+// SubmitTx submits a signed transaction to all the reachable tomo nodes.
 func (tc *Client) SubmitTx(ctx context.Context, signedTx hexutil.Bytes) (string, error) {
 	hash := tomochaincommon.Hash{}
-	err := tc.c.CallContext(ctx, &hash, common.RPC_METHOD_SEND_SIGNED_TRANSACTION, signedTx)
+	err := tc.c.Broadcast(ctx, &hash, common.RPC_METHOD_SEND_SIGNED_TRANSACTION, signedTx)
 	if err != nil {
 		return "", err
 	}
@@ -979,14 +1232,11 @@ func (tc *Client) SubmitTx(ctx context.Context, signedTx hexutil.Bytes) (string,
 
 // GetBlockReward returns rewards of checkpoint block
 func (tc *Client) GetBlockReward(ctx context.Context, hash tomochaincommon.Hash) (map[string]map[string]*big.Int, error) {
-	rewards := map[string]map[string]map[string]*big.Int{}
-	if err := tc.c.CallContext(ctx, &rewards, common.RPC_METHOD_GET_REWARD_BY_HASH, hash); err != nil {
+	reward, err := tc.getEpochReward(ctx, hash)
+	if err != nil {
 		return nil, err
 	}
-	if rewards["rewards"] != nil {
-		return rewards["rewards"], nil
-	}
-	return nil, nil
+	return reward.Rewards, nil
 }
 
 // Status returns geth status information
@@ -998,7 +1248,13 @@ func (tc *Client) Status(ctx context.Context) (
 	[]*RosettaTypes.Peer,
 	error,
 ) {
-	header, err := tc.blockHeader(ctx, nil)
+	ctx = tc.c.pin(ctx)
+	latest, posvLatest, err := tc.blockHeader(ctx, nil)
+	if err != nil {
+		return nil, -1, nil, nil, err
+	}
+
+	header, posvHeader, err := tc.tipHeader(ctx, latest, posvLatest)
 	if err != nil {
 		return nil, -1, nil, nil, err
 	}
@@ -1008,37 +1264,62 @@ func (tc *Client) Status(ctx context.Context) (
 		return nil, -1, nil, nil, err
 	}
 
-	var syncStatus *RosettaTypes.SyncStatus
+	syncStatus := tc.syncStatus(progress, latest)
+	peers, _ := tc.peers(ctx)
+
+	return &RosettaTypes.BlockIdentifier{
+			Hash:  posvHeader.Hash.Hex(),
+			Index: header.Number.Int64(),
+		},
+		convertTime(header.Time.Uint64()),
+		syncStatus,
+		peers,
+		nil
+}
+
+// syncStatus describes the sync progress of the node. The node is synced
+// when it is not syncing and its latest block is not older than the sync
+// staleness threshold, if any.
+func (tc *Client) syncStatus(progress *tomochain.SyncProgress, latest *tomochaintypes.Header) *RosettaTypes.SyncStatus {
+	synced := false
 	if progress != nil {
 		currentIndex := int64(progress.CurrentBlock)
 		targetIndex := int64(progress.HighestBlock)
+		stage := common.SYNC_STAGE_BLOCKS
+		if progress.KnownStates > 0 && progress.PulledStates < progress.KnownStates {
+			stage = common.SYNC_STAGE_STATE
+		}
 
-		syncStatus = &RosettaTypes.SyncStatus{
+		return &RosettaTypes.SyncStatus{
 			CurrentIndex: &currentIndex,
 			TargetIndex:  &targetIndex,
+			Stage:        &stage,
+			Synced:       &synced,
 		}
 	}
 
-	peers, err := tc.peers(ctx)
-	if err != nil {
-		return nil, -1, nil, nil, err
+	stage := common.SYNC_STAGE_SYNCED
+	headAge := time.Since(time.Unix(latest.Time.Int64(), 0))
+	if tc.syncStaleness > 0 && headAge > tc.syncStaleness {
+		stage = common.SYNC_STAGE_STALE
+	} else {
+		synced = true
 	}
+	currentIndex := latest.Number.Int64()
 
-	return &RosettaTypes.BlockIdentifier{
-			Hash:  header.Hash().Hex(),
-			Index: header.Number.Int64(),
-		},
-		convertTime(header.Time.Uint64()),
-		syncStatus,
-		peers,
-		nil
+	return &RosettaTypes.SyncStatus{
+		CurrentIndex: &currentIndex,
+		TargetIndex:  &currentIndex,
+		Stage:        &stage,
+		Synced:       &synced,
+	}
 }
 
 // syncProgress retrieves the current progress of the sync algorithm. If there's
 // no sync currently running, it returns nil.
 func (tc *Client) syncProgress(ctx context.Context) (*tomochain.SyncProgress, error) {
 	var raw json.RawMessage
-	if err := tc.c.CallContext(ctx, &raw, "eth_syncing"); err != nil {
+	if err := tc.c.CallContext(ctx, &raw, common.RPC_METHOD_SYNCING); err != nil {
 		return nil, err
 	}
 
@@ -1066,41 +1347,17 @@ func (tc *Client) Call(
 	ctx context.Context,
 	request *RosettaTypes.CallRequest,
 ) (*RosettaTypes.CallResponse, error) {
-	switch request.Method { // nolint:gocritic
-	case common.RPC_METHOD_GET_TRANSACTION_RECEIPT:
-		var input GetTransactionReceiptInput
-		if err := RosettaTypes.UnmarshalMap(request.Parameters, &input); err != nil {
-			return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
-		}
-
-		if len(input.TxHash) == 0 {
-			return nil, fmt.Errorf("%w:tx_hash missing from params", ErrCallParametersInvalid)
-		}
-
-		receipt, err := tc.transactionReceipt(ctx, tomochaincommon.HexToHash(input.TxHash))
-		if err != nil {
-			return nil, err
-		}
-
-		// We cannot use RosettaTypes.MarshalMap because geth uses a custom
-		// marshaler to convert *types.Receipt to JSON.
-		jsonOutput, err := receipt.MarshalJSON()
-		if err != nil {
-			return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
-		}
-
-		var receiptMap map[string]interface{}
-		if err := json.Unmarshal(jsonOutput, &receiptMap); err != nil {
-			return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
-		}
+	ctx = tc.c.pin(ctx)
+	method, ok := tc.calls.Method(request.Method)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCallMethodInvalid, request.Method)
+	}
 
-		// We must encode data over the wire so we can unmarshal correctly
-		return &RosettaTypes.CallResponse{
-			Result: receiptMap,
-		}, nil
+	if err := method.ValidateParameters(request.Parameters); err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("%w: %s", ErrCallMethodInvalid, request.Method)
+	return method.Handler(ctx, tc, request.Parameters)
 }
 
 // derive TomoChain Address from uncompressed public key (65 bytes)
@@ -0,0 +1,164 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaintypes "github.com/tomochain/tomochain/core/types"
+	"github.com/tomochain/tomochain/params"
+)
+
+// loadFixture reads a result of tomo recorded in testdata.
+func loadFixture(t *testing.T, name string) json.RawMessage {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return json.RawMessage(data)
+}
+
+func newTestClient(t *testing.T, node *fakeTomo) *Client {
+	client, err := NewClient([]string{node.URL}, &ChainRules{
+		Params:           params.TomoMainnetChainConfig,
+		FeeHardForkBlock: common.HardForkUpdateTxFee,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestStatusReportsFinalBlockHash(t *testing.T) {
+	block := loadFixture(t, "block_1000.json")
+	var fixture struct {
+		Hash   string `json:"hash"`
+		Number string `json:"number"`
+	}
+	if err := json.Unmarshal(block, &fixture); err != nil {
+		t.Fatal(err)
+	}
+
+	// the hash of the decoded header misses the PoSV fields,
+	// the final hash is only the one served by tomo
+	var head tomochaintypes.Header
+	if err := json.Unmarshal(block, &head); err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash().Hex() == fixture.Hash {
+		t.Fatal("the fixture must not have the hash of its decoded header")
+	}
+
+	node := newFakeTomo(t)
+	defer node.Close()
+	node.handle(common.RPC_METHOD_GET_CHAIN_ID, func([]json.RawMessage) (interface{}, error) {
+		return "0x58", nil
+	})
+	node.handle(common.RPC_METHOD_SYNCING, func([]json.RawMessage) (interface{}, error) {
+		return false, nil
+	})
+	node.handle(common.RPC_METHOD_GET_BLOCK_BY_NUMBER, func(params []json.RawMessage) (interface{}, error) {
+		var arg string
+		if err := json.Unmarshal(params[0], &arg); err != nil {
+			return nil, err
+		}
+		if arg != "latest" && arg != fixture.Number {
+			return nil, nil
+		}
+		return block, nil
+	})
+	node.handle(common.RPC_METHOD_GET_BLOCK_BY_HASH, func(params []json.RawMessage) (interface{}, error) {
+		var arg string
+		if err := json.Unmarshal(params[0], &arg); err != nil {
+			return nil, err
+		}
+		if arg != fixture.Hash {
+			return nil, nil
+		}
+		return block, nil
+	})
+
+	client := newTestClient(t, node)
+	defer client.Close()
+	ctx := context.Background()
+
+	current, _, _, _, err := client.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Hash != fixture.Hash {
+		t.Fatalf("status hash %s, want %s", current.Hash, fixture.Hash)
+	}
+
+	byIndex, err := client.Block(ctx, &RosettaTypes.PartialBlockIdentifier{Index: &current.Index})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byHash, err := client.Block(ctx, &RosettaTypes.PartialBlockIdentifier{Hash: &current.Hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []*RosettaTypes.Block{byIndex, byHash} {
+		if *b.BlockIdentifier != *current {
+			t.Fatalf("block %v, want %v", b.BlockIdentifier, current)
+		}
+	}
+}
+
+func TestPeersFallbacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		adminPeer bool
+		peerCount string
+		ids       []string
+		source    string
+	}{
+		{name: "admin_peers", adminPeer: true, peerCount: "0x2", ids: []string{"enode-1"}, source: common.RPC_METHOD_ADMIN_PEERS},
+		{name: "net_peerCount", peerCount: "0x2", ids: []string{"peer-0", "peer-1"}, source: common.RPC_METHOD_NET_PEER_COUNT},
+		{name: "no counted peer", peerCount: "0x0", source: common.RPC_METHOD_NET_PEER_COUNT},
+		{name: "none", source: common.PEER_SOURCE_NONE},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := newFakeTomo(t)
+			defer node.Close()
+			node.chain(88, 100, "aa")
+			if test.adminPeer {
+				node.handle(common.RPC_METHOD_ADMIN_PEERS, func([]json.RawMessage) (interface{}, error) {
+					return []map[string]interface{}{{"id": "enode-1", "name": "tomo", "caps": []string{}}}, nil
+				})
+			}
+			if len(test.peerCount) > 0 {
+				node.handle(common.RPC_METHOD_NET_PEER_COUNT, func([]json.RawMessage) (interface{}, error) {
+					return test.peerCount, nil
+				})
+			}
+
+			client := newTestClient(t, node)
+			defer client.Close()
+			peers, source := client.peers(context.Background())
+			if source != test.source {
+				t.Errorf("source %s, want %s", source, test.source)
+			}
+			if peers == nil || len(peers) != len(test.ids) {
+				t.Fatalf("%d peers, want %d", len(peers), len(test.ids))
+			}
+			for i, peer := range peers {
+				if peer.PeerID != test.ids[i] || peer.Metadata[common.METADATA_PEER_SOURCE] != test.source {
+					t.Errorf("peer %s from %v, want %s from %s", peer.PeerID, peer.Metadata[common.METADATA_PEER_SOURCE], test.ids[i], test.source)
+				}
+			}
+			if count := testutil.ToFloat64(peerCount.WithLabelValues(test.source)); count != float64(len(test.ids)) {
+				t.Errorf("%v peers observed from %s, want %d", count, test.source, len(test.ids))
+			}
+		})
+	}
+}
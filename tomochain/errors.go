@@ -7,4 +7,32 @@ var (
 	ErrCallParametersInvalid = errors.New("call parameters invalid")
 	ErrCallOutputMarshal     = errors.New("call output marshal")
 	ErrCallMethodInvalid     = errors.New("call method invalid")
+
+	// ErrInvalidAddress is returned when tomo returns
+	// an address which is not a valid hex address.
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrNegativeBalance is returned when the trace of a transaction
+	// leaves a self-destructed account with a negative balance.
+	ErrNegativeBalance = errors.New("negative balance for self-destructed account")
+
+	// ErrNoUpstream is returned when no tomo node
+	// of the configured chain can serve a request.
+	ErrNoUpstream = errors.New("no reachable tomo on the configured chain")
+
+	// ErrRewardsNotStored is returned when tomo has not stored the rewards
+	// of a checkpoint block, because it does not run with --store-reward or
+	// because it processed the block before it did.
+	ErrRewardsNotStored = errors.New("epoch rewards not stored by tomo")
+
+	// ErrTransactionNotFound is returned when a block
+	// has no transaction with the requested identifier.
+	ErrTransactionNotFound = errors.New("transaction not found")
+
+	// ErrTracerRejected is returned when tomo fails to run the call tracer.
+	ErrTracerRejected = errors.New("tomo rejected the call tracer")
+
+	// ErrSpecialRewardMismatch is returned when a special reward
+	// does not match the balances of tomo.
+	ErrSpecialRewardMismatch = errors.New("special reward does not match tomo balances")
 )
\ No newline at end of file
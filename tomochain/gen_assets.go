@@ -0,0 +1,63 @@
+// Copyright (c) 2020 TomoChain
+
+//go:build ignore
+// +build ignore
+
+// gen_assets bundles data files into a Go source file, so that they are
+// compiled into the binary and do not have to be shipped next to it.
+//
+// Usage: go run gen_assets.go -out assets.go file...
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	out := flag.String("out", "assets.go", "generated Go file")
+	pkg := flag.String("package", "tomochain", "package of the generated Go file")
+	flag.Parse()
+
+	files := flag.Args()
+	sort.Strings(files)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by gen_assets.go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", *pkg)
+	fmt.Fprintf(&src, "// assets are the data files bundled into the binary, by path.\n")
+	fmt.Fprintf(&src, "var assets = map[string]string{\n")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatalf("unable to read %s: %v", file, err)
+		}
+		fmt.Fprintf(&src, "%s: %s,\n", strconv.Quote(filepath.ToSlash(file)), literal(string(data)))
+	}
+	fmt.Fprintf(&src, "}\n")
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		log.Fatalf("unable to format generated source: %v", err)
+	}
+	if err := ioutil.WriteFile(*out, formatted, 0644); err != nil {
+		log.Fatalf("unable to write %s: %v", *out, err)
+	}
+}
+
+// literal returns a raw string literal of data when it can
+// hold it, so that the bundled files stay readable.
+func literal(data string) string {
+	if strings.ContainsAny(data, "`\r") {
+		return strconv.Quote(data)
+	}
+	return "`" + data + "`"
+}
@@ -0,0 +1,7 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+// The data files are bundled into assets.go, run make generate after
+// changing them.
+//go:generate go run gen_assets.go -out assets.go call_tracer.js special_rewards/mainnet.json
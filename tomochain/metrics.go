@@ -0,0 +1,125 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tomochain/tomochain/rpc"
+	"sync"
+	"time"
+)
+
+const (
+	// MetricsNamespace is the namespace of the metrics of tomochain-rosetta.
+	MetricsNamespace = "tomochain_rosetta"
+
+	rpcResultSuccess = "success"
+	rpcResultError   = "error"
+)
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "rpc",
+		Name:      "requests_total",
+		Help:      "Number of JSON-RPC calls to tomo by method and result.",
+	}, []string{"method", "result"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "rpc",
+		Name:      "duration_seconds",
+		Help:      "Latency of JSON-RPC calls to tomo by method, batches are labeled with their first method.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"method"})
+
+	traceWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "trace",
+		Name:      "semaphore_wait_seconds",
+		Help:      "Time spent waiting for the trace semaphore.",
+		Buckets:   []float64{.001, .01, .05, .1, .5, 1, 5, 10, 30, 60},
+	})
+
+	tracesInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "trace",
+		Name:      "in_flight",
+		Help:      "Number of traces running on tomo.",
+	})
+
+	blockParseDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "block",
+		Name:      "parse_duration_seconds",
+		Help:      "Time spent fetching, tracing and parsing a block.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	})
+
+	blockOperations = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "block",
+		Name:      "operations",
+		Help:      "Number of operations per parsed block.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+	})
+
+	peerCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "tomo",
+		Name:      "peers",
+		Help:      "Number of peers of tomo, labeled with the source of the latest count.",
+	}, []string{"source"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		rpcRequests,
+		rpcDuration,
+		traceWait,
+		tracesInFlight,
+		blockParseDuration,
+		blockOperations,
+		peerCount,
+	)
+}
+
+// observeCall records a JSON-RPC call which started at start.
+func observeCall(method string, start time.Time, err error) {
+	rpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	rpcRequests.WithLabelValues(method, rpcResult(err)).Inc()
+}
+
+// observeBatch records a batch of JSON-RPC calls which started at start.
+func observeBatch(b []rpc.BatchElem, start time.Time, err error) {
+	if len(b) == 0 {
+		return
+	}
+	rpcDuration.WithLabelValues(b[0].Method).Observe(time.Since(start).Seconds())
+	for _, elem := range b {
+		elemErr := err
+		if elemErr == nil {
+			elemErr = elem.Error
+		}
+		rpcRequests.WithLabelValues(elem.Method, rpcResult(elemErr)).Inc()
+	}
+}
+
+// peerCountLock keeps a single source in peerCount.
+var peerCountLock sync.Mutex
+
+// observePeers records the number of peers of tomo and their source,
+// the count of the previous source is dropped.
+func observePeers(source string, count int) {
+	peerCountLock.Lock()
+	defer peerCountLock.Unlock()
+	peerCount.Reset()
+	peerCount.WithLabelValues(source).Set(float64(count))
+}
+
+func rpcResult(err error) string {
+	if err != nil {
+		return rpcResultError
+	}
+	return rpcResultSuccess
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"fmt"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/params"
+	"math/big"
+	"strings"
+)
+
+type (
+	// ChainRules are the network specific rules used
+	// to turn blocks into Rosetta operations.
+	ChainRules struct {
+		// Params is the chain configuration of the network,
+		// the epoch length is read from Params.Posv.
+		Params *params.ChainConfig
+
+		// FeeHardForkBlock is the block from which transaction
+		// fees are paid to the masternode owner instead of the coinbase.
+		FeeHardForkBlock *big.Int
+
+		// SpecialRewards are the one-off rewards paid
+		// outside of transactions and epoch rewards.
+		SpecialRewards []*SpecialReward
+	}
+
+	// SpecialReward is a one-off reward paid to the sender
+	// of a transaction in the given block, see SpecialRewardsOf.
+	SpecialReward struct {
+		Block   uint64 `json:"block"`
+		Address string `json:"address"`
+		Amount  string `json:"amount"`
+		Reason  string `json:"reason,omitempty"`
+	}
+)
+
+// Validate ensures the rules can be used to parse blocks.
+func (r *ChainRules) Validate() error {
+	if r.Params == nil || r.Params.ChainId == nil {
+		return fmt.Errorf("chain config with a chain ID must be provided")
+	}
+	if r.Params.Posv == nil || r.Params.Posv.Epoch == 0 {
+		return fmt.Errorf("posv epoch must be positive")
+	}
+	if r.FeeHardForkBlock == nil || r.FeeHardForkBlock.Sign() < 0 {
+		return fmt.Errorf("fee hard-fork block must not be negative")
+	}
+	blocks := map[uint64]bool{}
+	for _, reward := range r.SpecialRewards {
+		if blocks[reward.Block] {
+			return fmt.Errorf("duplicate special reward of block %d", reward.Block)
+		}
+		blocks[reward.Block] = true
+		if !tomochaincommon.IsHexAddress(reward.Address) {
+			return fmt.Errorf("invalid address %s in special reward of block %d", reward.Address, reward.Block)
+		}
+		amount, ok := new(big.Int).SetString(reward.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return fmt.Errorf("invalid amount %s in special reward of block %d", reward.Amount, reward.Block)
+		}
+	}
+	return nil
+}
+
+// Epoch returns the number of blocks in an epoch.
+func (r *ChainRules) Epoch() uint64 {
+	return r.Params.Posv.Epoch
+}
+
+// specialRewardsByBlock indexes the special rewards by block number.
+func (r *ChainRules) specialRewardsByBlock() map[uint64]*specialRewardEntry {
+	rewards := map[uint64]*specialRewardEntry{}
+	for _, reward := range r.SpecialRewards {
+		amount, _ := new(big.Int).SetString(reward.Amount, 10)
+		rewards[reward.Block] = &specialRewardEntry{
+			address: strings.ToLower(reward.Address),
+			amount:  amount,
+		}
+	}
+	return rewards
+}
+
+type specialRewardEntry struct {
+	address string
+	amount  *big.Int
+}
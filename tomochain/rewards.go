@@ -0,0 +1,466 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/common/hexutil"
+	"github.com/tomochain/tomochain/crypto"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// rpcEpochReward is the reward data a tomo node stores
+// for each checkpoint block when started with --store-reward.
+type rpcEpochReward struct {
+	Signers map[string]*rpcSignerLog       `json:"signers"`
+	Rewards map[string]map[string]*big.Int `json:"rewards"`
+}
+
+// rpcSignerLog is the number of blocks a masternode signed
+// during an epoch and the reward it earned for them.
+type rpcSignerLog struct {
+	Sign   uint64   `json:"sign"`
+	Reward *big.Int `json:"reward"`
+}
+
+// rpcPosvHeader holds the PoSV fields of a block header which are
+// not decoded into *tomochaintypes.Header.
+type rpcPosvHeader struct {
+	Hash       tomochaincommon.Hash `json:"hash"`
+	Number     *hexutil.Big         `json:"number"`
+	Time       hexutil.Uint64       `json:"timestamp"`
+	Extra      hexutil.Bytes        `json:"extraData"`
+	Validators hexutil.Bytes        `json:"validators"`
+	Validator  hexutil.Bytes        `json:"validator"`
+	Penalties  hexutil.Bytes        `json:"penalties"`
+}
+
+// EpochRewards is the output of the call method "tomo_getEpochRewards".
+type EpochRewards struct {
+	BlockIdentifier *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+	Epoch           uint64                        `json:"epoch"`
+	Rewards         map[string]map[string]string  `json:"rewards"`
+	Signers         map[string]*SignerReward      `json:"signers"`
+	Totals          *RewardSplit                  `json:"totals"`
+}
+
+// SignerReward describes the reward of a single masternode
+// signer in an epoch.
+type SignerReward struct {
+	Owner string       `json:"owner"`
+	Sign  uint64       `json:"sign"`
+	Split *RewardSplit `json:"split"`
+}
+
+// RewardSplit is the distribution of a reward between the
+// masternode owner, its voters and the foundation wallet.
+type RewardSplit struct {
+	Owner      string `json:"owner"`
+	Voter      string `json:"voter"`
+	Foundation string `json:"foundation"`
+	Total      string `json:"total"`
+}
+
+// getEpochReward returns the stored reward of a checkpoint block
+func (tc *Client) getEpochReward(ctx context.Context, hash tomochaincommon.Hash) (*rpcEpochReward, error) {
+	reward := &rpcEpochReward{}
+	if err := tc.c.CallContext(ctx, reward, common.RPC_METHOD_GET_REWARD_BY_HASH, hash); err != nil {
+		return nil, err
+	}
+	// tomo returns an empty object when it has no reward file for
+	// the block, a stored reward always has signers and rewards
+	if reward.Signers == nil && reward.Rewards == nil {
+		return nil, fmt.Errorf("%w: checkpoint block %s", ErrRewardsNotStored, hash.Hex())
+	}
+	return reward, nil
+}
+
+// getCheckpointReward returns the reward of the checkpoint block number.
+// tomo only rewards the epochs after the first one, the reward file it
+// writes for the first checkpoint is empty.
+func (tc *Client) getCheckpointReward(ctx context.Context, number uint64, hash tomochaincommon.Hash) (*rpcEpochReward, error) {
+	if number <= tc.rules.Epoch() {
+		return &rpcEpochReward{}, nil
+	}
+	return tc.getEpochReward(ctx, hash)
+}
+
+// StoresRewards reports whether tomo stores the rewards of checkpoint
+// blocks, which requires it to run with --store-reward. It is checked
+// on the latest checkpoint block, and assumed until the second one.
+func (tc *Client) StoresRewards(ctx context.Context) (bool, error) {
+	tc.RLock()
+	stores := tc.storesRewards
+	tc.RUnlock()
+	if stores {
+		return true, nil
+	}
+
+	ctx = tc.c.pin(ctx)
+	head, err := tc.getPosvHeader(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(nil))
+	if err != nil {
+		return false, err
+	}
+	checkpoint := head.Number.ToInt().Uint64() / tc.rules.Epoch() * tc.rules.Epoch()
+	if checkpoint <= tc.rules.Epoch() {
+		return true, nil
+	}
+	posvHead, err := tc.getPosvHeader(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(new(big.Int).SetUint64(checkpoint)))
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tc.getEpochReward(ctx, posvHead.Hash)
+	if errors.Is(err, ErrRewardsNotStored) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	tc.Lock()
+	tc.storesRewards = true
+	tc.Unlock()
+	return true, nil
+}
+
+// getOwnerByCoinbase returns the owner of the masternode coinbase at the given block
+func (tc *Client) getOwnerByCoinbase(ctx context.Context, coinbase tomochaincommon.Address, number *big.Int) (string, error) {
+	var owner string
+	if err := tc.c.CallContext(ctx, &owner, common.RPC_METHOD_GET_OWNER_BY_COINBASE, coinbase, toBlockNumArg(number)); err != nil {
+		return "", err
+	}
+	return owner, nil
+}
+
+// getPosvHeader returns the header of a block without fetching its transactions.
+func (tc *Client) getPosvHeader(ctx context.Context, blockMethod string, arg interface{}) (*rpcPosvHeader, error) {
+	var head *rpcPosvHeader
+	if err := tc.c.CallContext(ctx, &head, blockMethod, arg, false); err != nil {
+		return nil, err
+	}
+	if head == nil || head.Number == nil {
+		return nil, fmt.Errorf("block %v not found", arg)
+	}
+	return head, nil
+}
+
+// getPosvHeaderByIndexOrHash returns the header of the block queried by a /call
+// method, either by index or by hash.
+func (tc *Client) getPosvHeaderByIndexOrHash(ctx context.Context, index *int64, hash string) (*rpcPosvHeader, error) {
+	switch {
+	case len(hash) > 0:
+		return tc.getPosvHeader(ctx, common.RPC_METHOD_GET_BLOCK_BY_HASH, tomochaincommon.HexToHash(hash))
+	case index != nil:
+		return tc.getPosvHeader(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(big.NewInt(*index)))
+	default:
+		return nil, fmt.Errorf("%w: index or hash missing from params", ErrCallParametersInvalid)
+	}
+}
+
+// rewardTransactionPrefix is hashed with the hash of a checkpoint
+// block into the identifier of its reward transaction.
+const rewardTransactionPrefix = "tomochain-rosetta/epoch-reward/"
+
+// RewardTransactionHash returns the identifier of the reward transaction
+// of a checkpoint block, keccak256("tomochain-rosetta/epoch-reward/" ||
+// block hash). The rewards are not paid by a transaction of tomo, so the
+// identifier never matches a transaction or block hash of the chain.
+func RewardTransactionHash(blockHash string) string {
+	hash := tomochaincommon.HexToHash(blockHash)
+	return crypto.Keccak256Hash([]byte(rewardTransactionPrefix), hash[:]).Hex()
+}
+
+// rewardRole returns the role of a reward holder of a masternode.
+func rewardRole(holder, owner, foundation string) string {
+	switch {
+	case strings.EqualFold(holder, foundation):
+		return common.REWARD_ROLE_FOUNDATION
+	case strings.EqualFold(holder, owner):
+		return common.REWARD_ROLE_OWNER
+	default:
+		return common.REWARD_ROLE_VOTER
+	}
+}
+
+// ownerCacheEpochs is the number of epochs
+// whose masternode owners are cached.
+const ownerCacheEpochs = 4
+
+// ownerCache holds the owners of the masternodes rewarded
+// in the latest epochs, indexed by epoch and coinbase.
+type ownerCache struct {
+	sync.Mutex
+	epochs map[uint64]map[tomochaincommon.Address]string
+}
+
+func newOwnerCache() *ownerCache {
+	return &ownerCache{epochs: map[uint64]map[tomochaincommon.Address]string{}}
+}
+
+func (c *ownerCache) get(epoch uint64, coinbase tomochaincommon.Address) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	owner, ok := c.epochs[epoch][coinbase]
+	return owner, ok
+}
+
+// add caches the owner of a coinbase, the owners of
+// the oldest epoch are dropped beyond ownerCacheEpochs.
+func (c *ownerCache) add(epoch uint64, coinbase tomochaincommon.Address, owner string) {
+	c.Lock()
+	defer c.Unlock()
+	owners, ok := c.epochs[epoch]
+	if !ok {
+		owners = map[tomochaincommon.Address]string{}
+		c.epochs[epoch] = owners
+		if len(c.epochs) > ownerCacheEpochs {
+			oldest := epoch
+			for e := range c.epochs {
+				if e < oldest {
+					oldest = e
+				}
+			}
+			delete(c.epochs, oldest)
+		}
+	}
+	owners[coinbase] = owner
+}
+
+// rewardOwner returns the checksummed owner of a masternode
+// rewarded in the checkpoint block number.
+func (tc *Client) rewardOwner(ctx context.Context, signer tomochaincommon.Address, number *big.Int) (string, error) {
+	epoch := number.Uint64() / tc.rules.Epoch()
+	if owner, ok := tc.owners.get(epoch, signer); ok {
+		return owner, nil
+	}
+
+	owner, err := tc.getOwnerByCoinbase(ctx, signer, number)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to get owner of masternode %s", err, signer.Hex())
+	}
+	owner, err = Checksum(owner)
+	if err != nil {
+		return "", err
+	}
+	tc.owners.add(epoch, signer, owner)
+	return owner, nil
+}
+
+// signerRewards is the reward of a masternode signer in an epoch.
+type signerRewards struct {
+	signer string
+	owner  string
+	sign   uint64
+
+	// amounts are the rewards paid per holder, shares are the
+	// amounts split by role, sorted by holder and role
+	amounts map[string]*big.Int
+	shares  []*rewardShare
+
+	ownerReward, voterReward, foundationReward *big.Int
+}
+
+// rewardShare is the part of a reward paid to a holder for a role.
+type rewardShare struct {
+	holder string
+	role   string
+	amount *big.Int
+}
+
+// splitEpochReward splits the rewards of the checkpoint block number between
+// the masternode owners, their voters and the foundation wallet. The signers
+// are sorted by address.
+func (tc *Client) splitEpochReward(ctx context.Context, number *big.Int, reward *rpcEpochReward) ([]*signerRewards, error) {
+	foundation := tc.p.Posv.FoudationWalletAddr.Hex()
+	signers := make([]*signerRewards, 0, len(reward.Rewards))
+	for signer, holders := range reward.Rewards {
+		signerAddr, err := Checksum(signer)
+		if err != nil {
+			return nil, err
+		}
+		owner, err := tc.rewardOwner(ctx, tomochaincommon.HexToAddress(signer), number)
+		if err != nil {
+			return nil, err
+		}
+
+		rewards := &signerRewards{
+			signer:           signerAddr,
+			owner:            owner,
+			amounts:          map[string]*big.Int{},
+			ownerReward:      new(big.Int),
+			voterReward:      new(big.Int),
+			foundationReward: new(big.Int),
+		}
+		var masterReward *big.Int
+		if signerLog, ok := reward.Signers[signer]; ok {
+			rewards.sign = signerLog.Sign
+			if signerLog.Reward != nil {
+				masterReward = new(big.Int).Mul(signerLog.Reward, big.NewInt(tomochaincommon.RewardMasterPercent))
+				masterReward.Div(masterReward, big.NewInt(100))
+			}
+		}
+
+		for holder, amount := range holders {
+			holderAddr, err := Checksum(holder)
+			if err != nil {
+				return nil, err
+			}
+			rewards.amounts[holderAddr] = amount
+
+			role := rewardRole(holderAddr, owner, foundation)
+			switch {
+			case role == common.REWARD_ROLE_FOUNDATION:
+				rewards.foundationReward.Add(rewards.foundationReward, amount)
+			case role == common.REWARD_ROLE_OWNER && masterReward != nil && amount.Cmp(masterReward) > 0:
+				// an owner voting for its own masternode receives both
+				// shares in a single amount, give the voter part back
+				voted := new(big.Int).Sub(amount, masterReward)
+				rewards.ownerReward.Add(rewards.ownerReward, masterReward)
+				rewards.voterReward.Add(rewards.voterReward, voted)
+				rewards.shares = append(rewards.shares, &rewardShare{holder: holderAddr, role: common.REWARD_ROLE_VOTER, amount: voted})
+				amount = masterReward
+			case role == common.REWARD_ROLE_OWNER:
+				rewards.ownerReward.Add(rewards.ownerReward, amount)
+			default:
+				rewards.voterReward.Add(rewards.voterReward, amount)
+			}
+			rewards.shares = append(rewards.shares, &rewardShare{holder: holderAddr, role: role, amount: amount})
+		}
+
+		// rewards are maps, sort them so that
+		// the split does not change
+		sort.Slice(rewards.shares, func(i, j int) bool {
+			if rewards.shares[i].holder != rewards.shares[j].holder {
+				return rewards.shares[i].holder < rewards.shares[j].holder
+			}
+			return rewards.shares[i].role < rewards.shares[j].role
+		})
+		signers = append(signers, rewards)
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return signers[i].signer < signers[j].signer
+	})
+
+	return signers, nil
+}
+
+// epochRewards returns the reward breakdown of a checkpoint block
+func (tc *Client) epochRewards(ctx context.Context, input *GetEpochRewardsInput) (*EpochRewards, error) {
+	id, err := tc.getPosvHeaderByIndexOrHash(ctx, input.Index, input.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	number := id.Number.ToInt()
+	if number.Sign() <= 0 || number.Uint64()%tc.rules.Epoch() != 0 {
+		return nil, fmt.Errorf("%w: block %d is not a checkpoint block", ErrCallParametersInvalid, number)
+	}
+
+	reward, err := tc.getCheckpointReward(ctx, number.Uint64(), id.Hash)
+	if err != nil {
+		return nil, err
+	}
+	signers, err := tc.splitEpochReward(ctx, number, reward)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EpochRewards{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  id.Hash.Hex(),
+			Index: number.Int64(),
+		},
+		Epoch:   number.Uint64() / tc.rules.Epoch(),
+		Rewards: map[string]map[string]string{},
+		Signers: map[string]*SignerReward{},
+	}
+	totalOwner, totalVoter, totalFoundation := new(big.Int), new(big.Int), new(big.Int)
+	for _, rewards := range signers {
+		amounts := map[string]string{}
+		for holder, amount := range rewards.amounts {
+			amounts[holder] = amount.String()
+		}
+
+		result.Rewards[rewards.signer] = amounts
+		result.Signers[rewards.signer] = &SignerReward{
+			Owner: rewards.owner,
+			Sign:  rewards.sign,
+			Split: newRewardSplit(rewards.ownerReward, rewards.voterReward, rewards.foundationReward),
+		}
+		totalOwner.Add(totalOwner, rewards.ownerReward)
+		totalVoter.Add(totalVoter, rewards.voterReward)
+		totalFoundation.Add(totalFoundation, rewards.foundationReward)
+	}
+	result.Totals = newRewardSplit(totalOwner, totalVoter, totalFoundation)
+
+	return result, nil
+}
+
+// rewardOps returns the reward operations of a checkpoint block, sorted
+// by signer, holder and role. Each operation carries the epoch, the
+// masternode signer the reward comes from and the role of the holder.
+// An owner voting for its own masternode gets an operation per role,
+// as split by the call method "tomo_getEpochRewards".
+func (tc *Client) rewardOps(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+) ([]*RosettaTypes.Operation, error) {
+	number := big.NewInt(blockIdentifier.Index)
+	reward, err := tc.getCheckpointReward(ctx, number.Uint64(), tomochaincommon.HexToHash(blockIdentifier.Hash))
+	if err != nil {
+		return nil, err
+	}
+
+	signers, err := tc.splitEpochReward(ctx, number, reward)
+	if err != nil {
+		return nil, err
+	}
+
+	epoch := number.Uint64() / tc.rules.Epoch()
+	ops := []*RosettaTypes.Operation{}
+	for _, rewards := range signers {
+		for _, share := range rewards.shares {
+			ops = append(ops, &RosettaTypes.Operation{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{
+					Index: int64(len(ops)),
+				},
+				Type:   common.MinerRewardOpType,
+				Status: &common.SUCCESS,
+				Account: &RosettaTypes.AccountIdentifier{
+					Address: share.holder,
+				},
+				Amount: &RosettaTypes.Amount{
+					Value:    share.amount.String(),
+					Currency: common.TomoNativeCoin,
+				},
+				Metadata: map[string]interface{}{
+					common.METADATA_EPOCH:       epoch,
+					common.METADATA_SIGNER:      rewards.signer,
+					common.METADATA_REWARD_ROLE: share.role,
+				},
+			})
+		}
+	}
+
+	return ops, nil
+}
+
+func newRewardSplit(owner, voter, foundation *big.Int) *RewardSplit {
+	total := new(big.Int).Add(owner, voter)
+	total.Add(total, foundation)
+	return &RewardSplit{
+		Owner:      owner.String(),
+		Voter:      voter.String(),
+		Foundation: foundation.String(),
+		Total:      total.String(),
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/params"
+)
+
+const (
+	testSigner = "0x1111111111111111111111111111111111111111"
+	testOwner  = "0x2222222222222222222222222222222222222222"
+	testVoter  = "0x3333333333333333333333333333333333333333"
+)
+
+// rewardTomo serves the reward of a checkpoint block whose masternode
+// owner votes for its own masternode, and counts the owner lookups.
+func rewardTomo(t *testing.T, lookups *int32) *fakeTomo {
+	foundation := params.TomoMainnetChainConfig.Posv.FoudationWalletAddr.Hex()
+	node := newFakeTomo(t)
+	node.chain(88, 1800, "aa")
+	node.handle(common.RPC_METHOD_GET_REWARD_BY_HASH, func([]json.RawMessage) (interface{}, error) {
+		// the signer earned 1000: 400 for its owner, 500 for its
+		// voters, of which 100 for its owner, and 100 for the foundation
+		return map[string]interface{}{
+			"signers": map[string]interface{}{
+				testSigner: map[string]interface{}{"sign": 12, "reward": 1000},
+			},
+			"rewards": map[string]interface{}{
+				testSigner: map[string]interface{}{
+					testOwner:  500,
+					testVoter:  400,
+					foundation: 100,
+				},
+			},
+		}, nil
+	})
+	node.handle(common.RPC_METHOD_GET_OWNER_BY_COINBASE, func([]json.RawMessage) (interface{}, error) {
+		atomic.AddInt32(lookups, 1)
+		return testOwner, nil
+	})
+	return node
+}
+
+func TestRewardOps(t *testing.T) {
+	var lookups int32
+	node := rewardTomo(t, &lookups)
+	defer node.Close()
+	client := newTestClient(t, node)
+	defer client.Close()
+
+	foundation, err := Checksum(params.TomoMainnetChainConfig.Posv.FoudationWalletAddr.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, _ := Checksum(testOwner)
+	voter, _ := Checksum(testVoter)
+	signer, _ := Checksum(testSigner)
+	want := []struct {
+		address string
+		role    string
+		amount  string
+	}{
+		{owner, common.REWARD_ROLE_OWNER, "400"},
+		{owner, common.REWARD_ROLE_VOTER, "100"},
+		{voter, common.REWARD_ROLE_VOTER, "400"},
+		{foundation, common.REWARD_ROLE_FOUNDATION, "100"},
+	}
+
+	block := &RosettaTypes.BlockIdentifier{Index: 1800, Hash: "0x" + strings.Repeat("aa", 32)}
+	for i := 0; i < 2; i++ {
+		ops, err := client.rewardOps(context.Background(), block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ops) != len(want) {
+			t.Fatalf("%d operations, want %d", len(ops), len(want))
+		}
+		got := map[string]bool{}
+		for j, op := range ops {
+			if op.OperationIdentifier.Index != int64(j) {
+				t.Errorf("operation %d has index %d", j, op.OperationIdentifier.Index)
+			}
+			if op.Metadata[common.METADATA_SIGNER] != signer || op.Metadata[common.METADATA_EPOCH] != uint64(2) {
+				t.Errorf("operation %d metadata %v", j, op.Metadata)
+			}
+			got[op.Account.Address+op.Metadata[common.METADATA_REWARD_ROLE].(string)+op.Amount.Value] = true
+		}
+		for _, w := range want {
+			if !got[w.address+w.role+w.amount] {
+				t.Errorf("operation of %s as %s for %s missing", w.address, w.role, w.amount)
+			}
+		}
+	}
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("%d owner lookups, want 1", n)
+	}
+
+	// the operations add up to the split of tomo_getEpochRewards
+	index := int64(1800)
+	rewards, err := client.epochRewards(context.Background(), &GetEpochRewardsInput{Index: &index})
+	if err != nil {
+		t.Fatal(err)
+	}
+	split := rewards.Signers[signer].Split
+	if split.Owner != "400" || split.Voter != "500" || split.Foundation != "100" || split.Total != "1000" {
+		t.Errorf("split %+v", split)
+	}
+	if rewards.Rewards[signer][owner] != "500" {
+		t.Errorf("reward of the owner %s, want 500", rewards.Rewards[signer][owner])
+	}
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("%d owner lookups, want 1", n)
+	}
+}
+
+func TestOwnerCacheEvictsOldestEpoch(t *testing.T) {
+	cache := newOwnerCache()
+	signer := tomochaincommon.Address{1}
+	for epoch := uint64(1); epoch <= ownerCacheEpochs+1; epoch++ {
+		cache.add(epoch, signer, testOwner)
+	}
+	if _, ok := cache.get(1, signer); ok {
+		t.Error("owners of the oldest epoch are cached")
+	}
+	for epoch := uint64(2); epoch <= ownerCacheEpochs+1; epoch++ {
+		if _, ok := cache.get(epoch, signer); !ok {
+			t.Errorf("owners of epoch %d are not cached", epoch)
+		}
+	}
+}
+
+func TestRewardOpsFirstCheckpoint(t *testing.T) {
+	node := newFakeTomo(t)
+	defer node.Close()
+	node.chain(88, 1800, "aa")
+	node.handle(common.RPC_METHOD_GET_REWARD_BY_HASH, func([]json.RawMessage) (interface{}, error) {
+		// tomo writes an empty reward file for the first checkpoint
+		return map[string]interface{}{}, nil
+	})
+	client := newTestClient(t, node)
+	defer client.Close()
+
+	tx, err := client.populateRewardTransaction(context.Background(), &RosettaTypes.BlockIdentifier{
+		Index: 900,
+		Hash:  "0x" + strings.Repeat("aa", 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Operations) != 0 {
+		t.Errorf("%d operations, want none", len(tx.Operations))
+	}
+
+	_, err = client.rewardOps(context.Background(), &RosettaTypes.BlockIdentifier{
+		Index: 1800,
+		Hash:  "0x" + strings.Repeat("aa", 32),
+	})
+	if !errors.Is(err, ErrRewardsNotStored) {
+		t.Errorf("error %v, want %v", err, ErrRewardsNotStored)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"math/big"
+	"strings"
+)
+
+// specialRewardFiles are the bundled special reward tables,
+// each one applies to the network of its chain ID.
+var specialRewardFiles = []string{
+	"special_rewards/mainnet.json",
+}
+
+// SpecialRewardTable is the schema of a special reward table.
+type SpecialRewardTable struct {
+	ChainID        uint64           `json:"chain_id"`
+	SpecialRewards []*SpecialReward `json:"special_rewards"`
+}
+
+// SpecialRewardsOf returns the bundled special rewards of the
+// network of chainID, none if the network has no table.
+func SpecialRewardsOf(chainID *big.Int) ([]*SpecialReward, error) {
+	for _, file := range specialRewardFiles {
+		table, err := loadSpecialRewardTable(file)
+		if err != nil {
+			return nil, err
+		}
+		if new(big.Int).SetUint64(table.ChainID).Cmp(chainID) == 0 {
+			return table.SpecialRewards, nil
+		}
+	}
+	return nil, nil
+}
+
+func loadSpecialRewardTable(file string) (*SpecialRewardTable, error) {
+	data, ok := assets[file]
+	if !ok {
+		return nil, fmt.Errorf("special reward table %s is not bundled", file)
+	}
+
+	table := &SpecialRewardTable{}
+	if err := json.Unmarshal([]byte(data), table); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse special reward table %s", err, file)
+	}
+	if table.ChainID == 0 {
+		return nil, fmt.Errorf("chain ID missing from special reward table %s", file)
+	}
+	for _, reward := range table.SpecialRewards {
+		if len(reward.Reason) == 0 {
+			return nil, fmt.Errorf("reason missing from special reward of block %d in %s", reward.Block, file)
+		}
+	}
+	return table, nil
+}
+
+// CheckSpecialReward ensures the balance of the address of a special
+// reward changes in its block by the amounts of its operations, the
+// special reward included. Tomo sets the balance of the address before
+// its transaction, so the reward is wrong if the address had a balance.
+func (tc *Client) CheckSpecialReward(ctx context.Context, reward *SpecialReward) error {
+	ctx = tc.c.pin(ctx)
+	if reward.Block == 0 {
+		return fmt.Errorf("%w: special reward of the genesis block", ErrSpecialRewardMismatch)
+	}
+
+	index := int64(reward.Block)
+	block, err := tc.Block(ctx, &RosettaTypes.PartialBlockIdentifier{Index: &index})
+	if err != nil {
+		return err
+	}
+
+	paid := false
+	change := new(big.Int)
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.Amount == nil || !strings.EqualFold(op.Account.Address, reward.Address) {
+				continue
+			}
+			if op.Status == nil || *op.Status != common.SUCCESS {
+				continue
+			}
+			amount, ok := new(big.Int).SetString(op.Amount.Value, 10)
+			if !ok {
+				return fmt.Errorf("invalid amount %s of operation", op.Amount.Value)
+			}
+			change.Add(change, amount)
+			if op.Type == common.MinerRewardOpType && op.Amount.Value == reward.Amount {
+				paid = true
+			}
+		}
+	}
+	if !paid {
+		return fmt.Errorf("%w: address %s sent no transaction in block %d", ErrSpecialRewardMismatch, reward.Address, reward.Block)
+	}
+
+	account := &RosettaTypes.AccountIdentifier{Address: reward.Address}
+	parent := index - 1
+	before, err := tc.Balance(ctx, account, &RosettaTypes.PartialBlockIdentifier{Index: &parent})
+	if err != nil {
+		return err
+	}
+	after, err := tc.Balance(ctx, account, &RosettaTypes.PartialBlockIdentifier{Index: &index})
+	if err != nil {
+		return err
+	}
+	delta, err := balanceChange(before, after)
+	if err != nil {
+		return err
+	}
+
+	if delta.Cmp(change) != 0 {
+		return fmt.Errorf(
+			"%w: balance of %s changed by %s in block %d, operations sum to %s",
+			ErrSpecialRewardMismatch,
+			reward.Address,
+			delta,
+			reward.Block,
+			change,
+		)
+	}
+	return nil
+}
+
+// balanceChange returns the change of the native balance between two
+// balance responses.
+func balanceChange(before, after *RosettaTypes.AccountBalanceResponse) (*big.Int, error) {
+	values := make([]*big.Int, 0, 2)
+	for _, res := range []*RosettaTypes.AccountBalanceResponse{before, after} {
+		if len(res.Balances) == 0 {
+			return nil, fmt.Errorf("balance missing at block %d", res.BlockIdentifier.Index)
+		}
+		value, ok := new(big.Int).SetString(res.Balances[0].Value, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %s", res.Balances[0].Value)
+		}
+		values = append(values, value)
+	}
+	return values[1].Sub(values[1], values[0]), nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"fmt"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/consensus/posv"
+	tomochaintypes "github.com/tomochain/tomochain/core/types"
+	"github.com/tomochain/tomochain/crypto"
+	"math/big"
+)
+
+const (
+	// TipLatest reports the latest block of the node as the tip.
+	TipLatest = "LATEST"
+
+	// TipConfirmations reports the block which has been
+	// confirmed by a number of blocks as the tip.
+	TipConfirmations = "CONFIRMATIONS"
+
+	// TipDoubleValidated reports the latest block carrying a valid
+	// signature of its second masternode (M2) as the tip.
+	TipDoubleValidated = "DOUBLE_VALIDATED"
+)
+
+// TipPolicy decides which block is reported as the tip of the chain
+// by /network/status and by /block requests without an index or hash.
+type TipPolicy struct {
+	Mode          string
+	Confirmations uint64
+}
+
+// DefaultTipPolicy reports the latest block of the node.
+var DefaultTipPolicy = &TipPolicy{Mode: TipLatest}
+
+// Validate ensures the tip policy is supported.
+func (p *TipPolicy) Validate() error {
+	switch p.Mode {
+	case TipLatest, TipDoubleValidated:
+		return nil
+	case TipConfirmations:
+		if p.Confirmations == 0 {
+			return fmt.Errorf("tip policy %s requires a positive number of confirmations", TipConfirmations)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s is not a valid tip policy", p.Mode)
+	}
+}
+
+// tipHeader returns the header of the tip of the chain under the tip policy,
+// given the latest header of the node.
+func (tc *Client) tipHeader(
+	ctx context.Context,
+	head *tomochaintypes.Header,
+	posvHead *rpcPosvHeader,
+) (*tomochaintypes.Header, *rpcPosvHeader, error) {
+	var err error
+	switch tc.tip.Mode {
+	case TipConfirmations:
+		number := head.Number.Uint64()
+		if number <= tc.tip.Confirmations {
+			return tc.blockHeader(ctx, big.NewInt(GenesisBlockIndex))
+		}
+		return tc.blockHeader(ctx, new(big.Int).SetUint64(number-tc.tip.Confirmations))
+	case TipDoubleValidated:
+		// a block is double validated when it is sealed, so the tip is
+		// usually the latest block, look back one epoch at most
+		number := head.Number.Uint64()
+		for i := uint64(0); i <= tc.rules.Epoch() && i <= number; i++ {
+			if i > 0 {
+				head, posvHead, err = tc.blockHeader(ctx, new(big.Int).SetUint64(number-i))
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			validated, err := tc.isDoubleValidated(ctx, head, posvHead)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: unable to verify double validation of block %d", err, number-i)
+			}
+			if validated {
+				return head, posvHead, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("no double validated block found in the last epoch before block %d", number)
+	default:
+		return head, posvHead, nil
+	}
+}
+
+// isDoubleValidated reports whether a block carries the signature of the
+// second masternode (M2) assigned to its creator in the checkpoint block.
+func (tc *Client) isDoubleValidated(
+	ctx context.Context,
+	head *tomochaintypes.Header,
+	posvHead *rpcPosvHeader,
+) (bool, error) {
+	// double validation starts from the second epoch
+	number := head.Number.Uint64()
+	if number <= tc.rules.Epoch() {
+		return true, nil
+	}
+	if len(posvHead.Validator) != common.ExtraSeal {
+		return false, nil
+	}
+
+	creator, err := GetCoinbaseFromHeader(head)
+	if err != nil {
+		return false, err
+	}
+	validator, err := GetValidatorFromHeader(head, posvHead.Validator)
+	if err != nil {
+		return false, err
+	}
+
+	checkpoint, err := tc.getCheckpointHeader(ctx, number)
+	if err != nil {
+		return false, err
+	}
+	m1m2, err := tc.m1m2(checkpoint, number)
+	if err != nil {
+		return false, err
+	}
+
+	assigned, ok := m1m2[creator]
+	return ok && assigned == validator, nil
+}
+
+// m1m2 maps the masternodes of a checkpoint block to the second masternode
+// (M2) assigned to them for the given block of its epoch. It follows
+// posv.GetM1M2FromCheckpointHeader, which assumes epochs of 900 blocks,
+// with the epoch length of the network.
+func (tc *Client) m1m2(checkpoint *rpcPosvHeader, number uint64) (map[tomochaincommon.Address]tomochaincommon.Address, error) {
+	masternodes := masternodesFromCheckpoint(checkpoint)
+	validators := posv.ExtractValidatorsFromBytes(checkpoint.Validators)
+	if len(validators) < len(masternodes) {
+		return nil, fmt.Errorf("checkpoint block %d assigns %d M2 to %d masternodes", checkpoint.Number.ToInt(), len(validators), len(masternodes))
+	}
+
+	m1m2 := map[tomochaincommon.Address]tomochaincommon.Address{}
+	count := uint64(len(masternodes))
+	if count == 0 {
+		return m1m2, nil
+	}
+	// the M2 are rotated along the epoch since TIPRandomize
+	shift := uint64(0)
+	if tc.p.IsTIPRandomize(new(big.Int).SetUint64(number)) {
+		shift = number % tc.rules.Epoch() / count % count
+	}
+	for i, m1 := range masternodes {
+		m1m2[m1] = masternodes[(uint64(validators[i])%count+shift)%count]
+	}
+	return m1m2, nil
+}
+
+// GetValidatorFromHeader recovers the second masternode (M2)
+// from its signature of the header.
+func GetValidatorFromHeader(header *tomochaintypes.Header, signature []byte) (tomochaincommon.Address, error) {
+	if len(header.Extra) < common.ExtraSeal {
+		return tomochaincommon.Address{}, fmt.Errorf("extra-data %d byte suffix signature missing", common.ExtraSeal)
+	}
+	pubkey, err := crypto.Ecrecover(posv.SigHash(header).Bytes(), signature)
+	if err != nil {
+		return tomochaincommon.Address{}, err
+	}
+	return PubToAddress(pubkey), nil
+}
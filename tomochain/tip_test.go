@@ -0,0 +1,99 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/common/hexutil"
+	"github.com/tomochain/tomochain/consensus/posv"
+	tomochaintypes "github.com/tomochain/tomochain/core/types"
+	"github.com/tomochain/tomochain/params"
+)
+
+// testCheckpoint returns a checkpoint header listing the masternodes,
+// which are assigned the M2 of the given indexes.
+func testCheckpoint(number uint64, masternodes []tomochaincommon.Address, m2 []int) *rpcPosvHeader {
+	extra := make([]byte, common.ExtraVanity, common.ExtraVanity+len(masternodes)*tomochaincommon.AddressLength+common.ExtraSeal)
+	for _, masternode := range masternodes {
+		extra = append(extra, masternode.Bytes()...)
+	}
+	extra = append(extra, make([]byte, common.ExtraSeal)...)
+
+	var validators []byte
+	for _, index := range m2 {
+		validators = append(validators, []byte(fmt.Sprintf("%04d", index))...)
+	}
+	return &rpcPosvHeader{
+		Number:     (*hexutil.Big)(new(big.Int).SetUint64(number)),
+		Extra:      extra,
+		Validators: validators,
+	}
+}
+
+func testClient(epoch uint64) *Client {
+	config := *params.TomoMainnetChainConfig
+	config.Posv = &params.PosvConfig{Period: 2, Epoch: epoch}
+	return &Client{p: &config, rules: &ChainRules{Params: &config}}
+}
+
+func TestM1M2(t *testing.T) {
+	masternodes := []tomochaincommon.Address{
+		tomochaincommon.HexToAddress("0x01"),
+		tomochaincommon.HexToAddress("0x02"),
+		tomochaincommon.HexToAddress("0x03"),
+	}
+	m2 := []int{2, 0, 7}
+
+	// the M2 of an epoch of 900 blocks are the ones of tomo
+	tc := testClient(900)
+	for _, number := range []uint64{901, 1799, 3464001, 3464450, 3464899} {
+		checkpoint := testCheckpoint(number-number%900, masternodes, m2)
+		want, err := posv.GetM1M2FromCheckpointHeader(&tomochaintypes.Header{
+			Number:     checkpoint.Number.ToInt(),
+			Extra:      checkpoint.Extra,
+			Validators: checkpoint.Validators,
+		}, &tomochaintypes.Header{Number: new(big.Int).SetUint64(number)}, tc.p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tc.m1m2(checkpoint, number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("M2 of block %d %v, want %v", number, got, want)
+		}
+	}
+
+	// other epoch lengths rotate the M2 along their epoch
+	tc = testClient(30)
+	tests := []struct {
+		number uint64
+		m2     []tomochaincommon.Address
+	}{
+		{31, []tomochaincommon.Address{masternodes[2], masternodes[0], masternodes[1]}},
+		{3464010 + 2, []tomochaincommon.Address{masternodes[2], masternodes[0], masternodes[1]}},
+		{3464010 + 3, []tomochaincommon.Address{masternodes[0], masternodes[1], masternodes[2]}},
+		{3464010 + 5, []tomochaincommon.Address{masternodes[0], masternodes[1], masternodes[2]}},
+	}
+	for _, test := range tests {
+		got, err := tc.m1m2(testCheckpoint(test.number-test.number%30, masternodes, m2), test.number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, m1 := range masternodes {
+			if got[m1] != test.m2[i] {
+				t.Errorf("M2 of %s in block %d %s, want %s", m1.Hex(), test.number, got[m1].Hex(), test.m2[i].Hex())
+			}
+		}
+	}
+
+	if _, err := tc.m1m2(testCheckpoint(30, masternodes, m2[:2]), 31); err == nil {
+		t.Error("missing M2 must fail")
+	}
+}
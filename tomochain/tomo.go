@@ -5,20 +5,30 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/tomochain/tomochain/log"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
 	tomoLogger       = "tomo"
 	tomoStdErrLogger = "tomochain log"
+
+	// TomoIPCPath is the IPC endpoint of the embedded tomo.
+	TomoIPCPath = "/app/tomo.ipc"
 )
 
+// tomoLogRecord matches a record written by tomo in the terminal format,
+// such as "INFO [01-11|04:21:05] Imported new chain segment  blocks=1".
+// The location of the record follows the time when tomo runs with --debug.
+var tomoLogRecord = regexp.MustCompile(`^(TRACE|DEBUG|INFO|WARN|ERROR|CRIT) *\[([^\]]*)\] *(.*)$`)
+
 // logPipe prints out logs from geth. We don't end when context
 // is canceled beacause there are often logs printed after this.
 func logPipe(pipe io.ReadCloser, identifier string) error {
@@ -26,18 +36,67 @@ func logPipe(pipe io.ReadCloser, identifier string) error {
 	for {
 		str, err := reader.ReadString('\n')
 		if err != nil {
-			log.Println("closing", identifier, err)
+			log.Debug("closing tomo logs", "source", identifier, "err", err)
 			return err
 		}
 
 		message := strings.ReplaceAll(str, "\n", "")
-		log.Println(identifier, message)
+		if len(strings.TrimSpace(message)) == 0 {
+			continue
+		}
+		lvl, module, message := parseTomoLog(message)
+		// the record is written to the root handler to keep the level of
+		// tomo, log.Crit would exit tomochain-rosetta
+		_ = log.Root().GetHandler().Log(&log.Record{
+			Time: time.Now(),
+			Lvl:  lvl,
+			Msg:  message,
+			Ctx:  []interface{}{"source", identifier, "module", module},
+			KeyNames: log.RecordKeyNames{
+				Time: "t",
+				Msg:  "msg",
+				Lvl:  "lvl",
+			},
+		})
+	}
+}
+
+// parseTomoLog returns the level, module and message of a line written by
+// tomo. The module is the package of the location of the record if it is
+// known, lines which are not records are returned as is at info level.
+func parseTomoLog(line string) (log.Lvl, string, string) {
+	match := tomoLogRecord.FindStringSubmatch(line)
+	if match == nil {
+		return log.LvlInfo, tomoLogger, line
+	}
+
+	lvl, err := log.LvlFromString(strings.ToLower(match[1]))
+	if err != nil {
+		lvl = log.LvlInfo
 	}
+
+	module := tomoLogger
+	if header := strings.Split(match[2], "|"); len(header) > 2 {
+		location := strings.Split(header[2], ":")[0]
+		if dir := path.Dir(location); dir != "." {
+			module = dir
+		} else {
+			module = strings.TrimSuffix(location, ".go")
+		}
+	}
+
+	return lvl, module, strings.TrimSpace(match[3])
 }
 
 // StartTomo starts a geth daemon in another goroutine
 // and logs the results to the console.
-func StartTomo(ctx context.Context, arguments string, g *errgroup.Group) error {
+func StartTomo(
+	ctx context.Context,
+	binary string,
+	arguments string,
+	genesisFile string,
+	g *errgroup.Group,
+) error {
 	parsedArgs := strings.Split(arguments, " ")
 
 	// get datadir
@@ -52,26 +111,37 @@ func StartTomo(ctx context.Context, arguments string, g *errgroup.Group) error {
 		}
 	}
 	if _, err := os.Stat(datadir); os.IsNotExist(err) {
-		fmt.Println("create data dir", datadir)
+		log.Info("creating tomo data directory", "datadir", datadir)
 		os.Mkdir(datadir, 755)
 	}
 
 	// initialize if not exist
 	if _, err := os.Stat(path.Join(datadir, "tomo")); os.IsNotExist(err) {
-		fmt.Println("Initialize tomochain datadir with genesis")
+		log.Info("initializing tomo data directory with genesis", "datadir", datadir, "genesis", genesisFile)
 		initCmd := exec.Command(
-			"/app/tomo",
+			binary,
 			"init",
-			"/app/genesis.json",
+			genesisFile,
 			"--datadir="+datadir,
 		)
 		if err := initCmd.Run(); err != nil {
-			fmt.Println("Failed to initialize tomochain datadir", err)
+			log.Error("unable to initialize tomo data directory", "datadir", datadir, "err", err)
 		}
 	}
 
+	// the client connects to the embedded tomo through IPC
+	hasIPCPath := false
+	for _, arg := range parsedArgs {
+		if strings.HasPrefix(arg, "--ipcpath") {
+			hasIPCPath = true
+		}
+	}
+	if !hasIPCPath {
+		parsedArgs = append(parsedArgs, "--ipcpath="+TomoIPCPath)
+	}
+
 	cmd := exec.Command(
-		"/app/tomo",
+		binary,
 		parsedArgs...,
 	) // #nosec G204
 
@@ -100,7 +170,7 @@ func StartTomo(ctx context.Context, arguments string, g *errgroup.Group) error {
 	g.Go(func() error {
 		<-ctx.Done()
 
-		log.Println("sending interrupt to tomo")
+		log.Info("sending interrupt to tomo")
 		return cmd.Process.Signal(os.Interrupt)
 	})
 
@@ -3,30 +3,126 @@
 package tomochain
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaincommon "github.com/tomochain/tomochain/common"
 	"github.com/tomochain/tomochain/eth"
 	"io/ioutil"
+	"math/big"
+	"time"
 )
 
 // convert raw eth data from client to rosetta
 
 const (
-	tracerPath = "tomochain/call_tracer.js"
+	// callTracerAsset is the bundled call tracer, updated
+	// with make update-tracer.
+	callTracerAsset = "call_tracer.js"
+
+	// TracerSourceEmbedded and TracerSourceFile are the sources
+	// of the call tracer run by tomo.
+	TracerSourceEmbedded = "embedded"
+	TracerSourceFile     = "file"
+
+	// tracerVersionSize is the number of bytes of the
+	// SHA-256 of the tracer code in its version.
+	tracerVersionSize = 8
+
+	// tracerCheckDepth is the number of latest blocks searched
+	// for a transaction to check the tracer with.
+	tracerCheckDepth = 128
 )
 
-var (
-	tracerTimeout = "120s"
+const (
+	defaultTracerTimeout = 120 * time.Second
 )
 
-func loadTraceConfig() (*eth.TraceConfig, error) {
-	loadedFile, err := ioutil.ReadFile(tracerPath)
+// Tracer is the JavaScript call tracer tomo runs to
+// trace the internal calls of transactions.
+type Tracer struct {
+	// Source is TracerSourceEmbedded or TracerSourceFile.
+	Source string
+
+	// Version identifies the code of the tracer, it is
+	// the beginning of the SHA-256 of the code.
+	Version string
+
+	code string
+}
+
+// LoadTracer returns the tracer of file, or the
+// bundled tracer if file is empty.
+func LoadTracer(file string) (*Tracer, error) {
+	if len(file) == 0 {
+		return newTracer(TracerSourceEmbedded, assets[callTracerAsset]), nil
+	}
+
+	code, err := ioutil.ReadFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("%w: could not load tracer file", err)
+		return nil, fmt.Errorf("%w: could not load tracer file %s", err, file)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("tracer file %s is empty", file)
 	}
+	return newTracer(TracerSourceFile, string(code)), nil
+}
 
-	loadedTracer := string(loadedFile)
+func newTracer(source, code string) *Tracer {
+	sum := sha256.Sum256([]byte(code))
+	return &Tracer{
+		Source:  source,
+		Version: hex.EncodeToString(sum[:tracerVersionSize]),
+		code:    code,
+	}
+}
+
+func loadTraceConfig(tracer *Tracer, timeout time.Duration) *eth.TraceConfig {
+	tracerTimeout := timeout.String()
 	return &eth.TraceConfig{
 		Timeout: &tracerTimeout,
-		Tracer:  &loadedTracer,
-	}, nil
+		Tracer:  &tracer.code,
+	}
+}
+
+// CheckTracer ensures tomo runs the call tracer, by tracing a transaction
+// of one of the latest blocks. ErrTracerRejected is returned if tomo fails
+// to run it. The check is skipped when the latest blocks have no transaction.
+func (tc *Client) CheckTracer(ctx context.Context) error {
+	ctx = tc.c.pin(ctx)
+	head, err := tc.getPosvHeader(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(nil))
+	if err != nil {
+		return err
+	}
+
+	number := head.Number.ToInt().Int64()
+	for i := int64(0); i < tracerCheckDepth && number-i > 0; i++ {
+		var block *struct {
+			Transactions []tomochaincommon.Hash `json:"transactions"`
+		}
+		err := tc.c.CallContext(ctx, &block, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(big.NewInt(number-i)), false)
+		if err != nil {
+			return err
+		}
+		if block == nil || len(block.Transactions) == 0 {
+			continue
+		}
+
+		txHash := block.Transactions[0]
+		_, _, err = tc.getTransactionTraces(ctx, txHash)
+		if err == nil || ctx.Err() != nil || isConnectionError(ctx, err) {
+			return err
+		}
+		return fmt.Errorf(
+			"%w: %s tracer %s failed on transaction %s: %s",
+			ErrTracerRejected,
+			tc.tracer.Source,
+			tc.tracer.Version,
+			txHash.Hex(),
+			err.Error(),
+		)
+	}
+	return nil
 }
@@ -35,16 +35,16 @@ var (
 	}
 
 	// TestnetGenesisBlockIdentifier is the *types.BlockIdentifier
-	// of the testnet genesis block.
+	// of the testnet genesis block, computed from genesis_files/testnet.json.
 	TestnetGenesisBlockIdentifier = &types.BlockIdentifier{
-		Hash:  params.TestnetGenesisHash.Hex(),
+		Hash:  "0x8d937ee5fb3ed439d7250b155e6097659b55a815c656debd34ff89c1f66e0c19",
 		Index: GenesisBlockIndex,
 	}
 
 	// DevnetGenesisBlockIdentifier is the *types.BlockIdentifier
-	// of the testnet genesis block.
+	// of the devnet genesis block, computed from genesis_files/devnet.json.
 	DevnetGenesisBlockIdentifier = &types.BlockIdentifier{
-		Hash:  "",
+		Hash:  "0x520b3618c62604f3625e1cf1d77aded5854d899ff98c29f1638e4a95ba28558a",
 		Index: GenesisBlockIndex,
 	}
 
@@ -58,9 +58,6 @@ var (
 	DevnetTomoArguments = `--config=/app/tomochain/tomochain.toml --gcmode=archive  --store-reward --tomox.dbengine=leveldb`
 )
 
-var CallMethods = []string{
-	common.RPC_METHOD_GET_TRANSACTION_RECEIPT,
-}
 type rpcBlock struct {
 	Hash         tomochaincommon.Hash      `json:"hash"`
 	Transactions []rpcTransaction `json:"transactions"`
@@ -216,6 +213,36 @@ type GetTransactionReceiptInput struct {
 	TxHash string `json:"tx_hash"`
 }
 
+// GetEpochRewardsInput is the input to the call
+// method "tomo_getEpochRewards". Either the index
+// or the hash of a checkpoint block must be provided.
+type GetEpochRewardsInput struct {
+	Index *int64 `json:"index,omitempty"`
+	Hash  string `json:"hash,omitempty"`
+}
+
+// GetMasternodesInput is the input to the call
+// method "tomo_getMasternodes".
+type GetMasternodesInput struct {
+	Index *int64 `json:"index,omitempty"`
+	Hash  string `json:"hash,omitempty"`
+}
+
+// GetCandidatesInput is the input to the call
+// method "tomo_getCandidates".
+type GetCandidatesInput struct {
+	Index *int64 `json:"index,omitempty"`
+	Hash  string `json:"hash,omitempty"`
+}
+
+// GetCandidateVotersInput is the input to the call
+// method "tomo_getCandidateVoters".
+type GetCandidateVotersInput struct {
+	Index     *int64 `json:"index,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	Candidate string `json:"candidate"`
+}
+
 
 // CallType returns a boolean indicating
 // if the provided trace type is a call type.
@@ -0,0 +1,586 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain/common/hexutil"
+	"github.com/tomochain/tomochain/log"
+	"github.com/tomochain/tomochain/rpc"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+)
+
+type (
+	// upstream is a tomo node the client sends requests to.
+	upstream struct {
+		sync.Mutex
+		url     string
+		timeout time.Duration
+		c       *rpc.Client
+		health  *UpstreamHealth
+	}
+
+	// UpstreamHealth is the result of the last health check of an upstream node.
+	UpstreamHealth struct {
+		URL       string        `json:"url"`
+		Checked   bool          `json:"checked"`
+		Reachable bool          `json:"reachable"`
+		Syncing   bool          `json:"syncing"`
+		ChainID   string        `json:"chain_id,omitempty"`
+		Head      uint64        `json:"head"`
+		HeadHash  string        `json:"head_hash,omitempty"`
+		HeadTime  time.Time     `json:"head_time"`
+		Latency   time.Duration `json:"latency"`
+		Error     string        `json:"error,omitempty"`
+
+		// WrongChain is set when the chain ID of the node is not the
+		// configured one, Forked when the node does not follow the chain
+		// of most nodes. Such nodes never serve requests.
+		WrongChain bool `json:"wrong_chain,omitempty"`
+		Forked     bool `json:"forked,omitempty"`
+	}
+
+	// upstreamPool spreads requests over the upstream nodes. Data
+	// requests are pinned to the most advanced healthy node so that a
+	// response never mixes data of nodes following different forks.
+	upstreamPool struct {
+		sync.RWMutex
+		upstreams []*upstream
+		chainID   *big.Int
+		preferred *upstream
+		done      chan struct{}
+	}
+
+	pinnedUpstreamKey struct{}
+)
+
+// dialUpstream connects to a tomo node using the transport given by the
+// scheme of its URL: http(s), ws(s), or IPC for "ipc://" URLs and paths.
+func dialUpstream(ctx context.Context, rawurl string, timeout time.Duration) (*rpc.Client, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tomo URL %s", err, rawurl)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return rpc.DialHTTPWithClient(rawurl, &http.Client{
+			Timeout: timeout,
+		})
+	case "ws", "wss":
+		return rpc.DialWebsocket(ctx, rawurl, "")
+	case "ipc":
+		return rpc.DialIPC(ctx, u.Host+u.Path)
+	case "":
+		return rpc.DialIPC(ctx, rawurl)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %s of tomo URL %s", u.Scheme, rawurl)
+	}
+}
+
+// validateUpstreamURL ensures a transport can be picked for the URL.
+func validateUpstreamURL(rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return fmt.Errorf("%w: invalid tomo URL %s", err, rawurl)
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss", "ipc", "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported scheme %s of tomo URL %s", u.Scheme, rawurl)
+	}
+}
+
+// client returns the RPC client of the node. The node is dialed on first
+// use because IPC and WebSocket connections fail while the node is starting,
+// the RPC client then reconnects by itself if the connection is lost.
+func (u *upstream) client(ctx context.Context) (*rpc.Client, error) {
+	u.Lock()
+	defer u.Unlock()
+	if u.c == nil {
+		c, err := dialUpstream(ctx, u.url, u.timeout)
+		if err != nil {
+			return nil, err
+		}
+		u.c = c
+	}
+	return u.c, nil
+}
+
+// newUpstreamPool creates the pool of upstream nodes and starts checking their health.
+func newUpstreamPool(urls []string, timeout time.Duration, chainID *big.Int) (*upstreamPool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("at least one tomo URL must be provided")
+	}
+
+	p := &upstreamPool{
+		chainID: chainID,
+		done:    make(chan struct{}),
+	}
+	for _, rawurl := range urls {
+		if err := validateUpstreamURL(rawurl); err != nil {
+			return nil, err
+		}
+		p.upstreams = append(p.upstreams, &upstream{
+			url:     rawurl,
+			timeout: timeout,
+			health:  &UpstreamHealth{URL: rawurl},
+		})
+	}
+
+	p.checkAll()
+	go p.healthCheckLoop()
+
+	return p, nil
+}
+
+// Close stops the health checks and closes the connections to the nodes.
+func (p *upstreamPool) Close() {
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+	for _, u := range p.upstreams {
+		u.Lock()
+		if u.c != nil {
+			u.c.Close()
+		}
+		u.Unlock()
+	}
+}
+
+func (p *upstreamPool) healthCheckLoop() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.checkAll()
+		}
+	}
+}
+
+// checkAll checks the health of all upstream nodes concurrently.
+func (p *upstreamPool) checkAll() {
+	var wg sync.WaitGroup
+	results := make([]*UpstreamHealth, len(p.upstreams))
+	for i, u := range p.upstreams {
+		wg.Add(1)
+		go func(i int, u *upstream) {
+			defer wg.Done()
+			results[i] = u.check(p.chainID)
+		}(i, u)
+	}
+	wg.Wait()
+
+	p.markForks(results)
+
+	p.Lock()
+	defer p.Unlock()
+	for i, u := range p.upstreams {
+		u.health = results[i]
+	}
+}
+
+// markForks compares the hashes the reachable nodes of the chain report
+// for the head of the lowest of them. The nodes outside of the largest
+// group agreeing on the hash, the group with the highest head on a tie,
+// are marked as forked.
+func (p *upstreamPool) markForks(results []*UpstreamHealth) {
+	var (
+		candidates []int
+		lowest     uint64
+	)
+	for i, health := range results {
+		if !health.Reachable || health.WrongChain {
+			continue
+		}
+		if len(candidates) == 0 || health.Head < lowest {
+			lowest = health.Head
+		}
+		candidates = append(candidates, i)
+	}
+	if len(candidates) < 2 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	hashes := make([]string, len(results))
+	var wg sync.WaitGroup
+	for _, i := range candidates {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hash, err := p.upstreams[i].hashAt(ctx, lowest)
+			if err != nil {
+				results[i].Error = fmt.Sprintf("unable to get block %d: %v", lowest, err)
+				return
+			}
+			hashes[i] = hash
+		}(i)
+	}
+	wg.Wait()
+
+	votes := map[string]int{}
+	highest := map[string]uint64{}
+	for _, i := range candidates {
+		if len(hashes[i]) == 0 {
+			continue
+		}
+		votes[hashes[i]]++
+		if results[i].Head > highest[hashes[i]] {
+			highest[hashes[i]] = results[i].Head
+		}
+	}
+	var canonical string
+	for hash, count := range votes {
+		best := votes[canonical]
+		if count > best || (count == best && highest[hash] > highest[canonical]) ||
+			(count == best && highest[hash] == highest[canonical] && hash < canonical) {
+			canonical = hash
+		}
+	}
+
+	for _, i := range candidates {
+		if len(hashes[i]) == 0 || hashes[i] == canonical {
+			continue
+		}
+		results[i].Forked = true
+		results[i].Error = fmt.Sprintf("block %d is %s, most nodes have %s", lowest, hashes[i], canonical)
+		log.Warn("tomo disagrees on the hash of a block", "url", results[i].URL, "number", lowest, "hash", hashes[i], "canonical", canonical)
+	}
+}
+
+// hashAt returns the hash of the block of the given number on the node.
+func (u *upstream) hashAt(ctx context.Context, number uint64) (string, error) {
+	c, err := u.client(ctx)
+	if err != nil {
+		return "", err
+	}
+	var head *rpcPosvHeader
+	err = c.CallContext(ctx, &head, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(new(big.Int).SetUint64(number)), false)
+	if err != nil {
+		return "", err
+	}
+	if head == nil {
+		return "", errors.New("block not found")
+	}
+	return head.Hash.Hex(), nil
+}
+
+// check reports the head, sync status and latency of an upstream node.
+func (u *upstream) check(chainID *big.Int) *UpstreamHealth {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	health := &UpstreamHealth{URL: u.url, Checked: true}
+	c, err := u.client(ctx)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	start := time.Now()
+	var head *rpcPosvHeader
+	err = c.CallContext(ctx, &head, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(nil), false)
+	health.Latency = time.Since(start)
+	if err == nil && (head == nil || head.Number == nil) {
+		err = errors.New("latest block not found")
+	}
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Reachable = true
+	health.Head = head.Number.ToInt().Uint64()
+	health.HeadHash = head.Hash.Hex()
+	health.HeadTime = time.Unix(int64(head.Time), 0)
+
+	var id hexutil.Uint64
+	if err := c.CallContext(ctx, &id, common.RPC_METHOD_GET_CHAIN_ID); err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.ChainID = fmt.Sprint(uint64(id))
+	if chainID != nil && uint64(id) != chainID.Uint64() {
+		health.WrongChain = true
+		health.Error = fmt.Sprintf("chain ID %d does not match chain ID %s", uint64(id), chainID.String())
+		return health
+	}
+
+	var syncing json.RawMessage
+	if err := c.CallContext(ctx, &syncing, common.RPC_METHOD_SYNCING); err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	var notSyncing bool
+	health.Syncing = json.Unmarshal(syncing, &notSyncing) != nil
+
+	return health
+}
+
+// healthy reports whether the node can serve requests.
+func (h *UpstreamHealth) healthy() bool {
+	return h.Reachable && len(h.Error) == 0 && !h.Syncing
+}
+
+// Health returns the result of the last health check of each upstream node.
+func (p *upstreamPool) Health() []*UpstreamHealth {
+	p.RLock()
+	defer p.RUnlock()
+	health := make([]*UpstreamHealth, len(p.upstreams))
+	for i, u := range p.upstreams {
+		h := *u.health
+		health[i] = &h
+	}
+	return health
+}
+
+// ranked returns the upstream nodes from the most to the least suitable.
+// Healthy nodes come first, then reachable nodes, then the others. Nodes
+// of the same kind are ranked by head, the preferred node wins ties so
+// requests do not move between nodes at the same height. The nodes of
+// another chain or of a fork are left out, ranked can then be empty.
+func (p *upstreamPool) ranked() []*upstream {
+	p.Lock()
+	defer p.Unlock()
+
+	rank := func(u *upstream) int {
+		switch {
+		case !u.health.Checked || u.health.healthy():
+			return 0
+		case u.health.Reachable:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	ranked := make([]*upstream, 0, len(p.upstreams))
+	for _, u := range p.upstreams {
+		if !u.health.WrongChain && !u.health.Forked {
+			ranked = append(ranked, u)
+		}
+	}
+	if len(ranked) == 0 {
+		return ranked
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if a.health.Head != b.health.Head {
+			return a.health.Head > b.health.Head
+		}
+		if a == p.preferred || b == p.preferred {
+			return a == p.preferred
+		}
+		return a.health.Latency < b.health.Latency
+	})
+
+	if p.preferred != ranked[0] {
+		if p.preferred != nil {
+			log.Info("switching preferred tomo", "from", p.preferred.url, "to", ranked[0].url)
+		}
+		p.preferred = ranked[0]
+	}
+
+	return ranked
+}
+
+// pin returns a context pinning all the requests made with
+// it to the most suitable node, unless it is already pinned.
+func (p *upstreamPool) pin(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(pinnedUpstreamKey{}).(*upstream); ok {
+		return ctx
+	}
+	ranked := p.ranked()
+	if len(ranked) == 0 {
+		// the requests fail with ErrNoUpstream
+		return ctx
+	}
+	return context.WithValue(ctx, pinnedUpstreamKey{}, ranked[0])
+}
+
+// rpcClient returns the client of the node pinned by ctx,
+// or of the most suitable node.
+func (p *upstreamPool) rpcClient(ctx context.Context) (*rpc.Client, error) {
+	if u, ok := ctx.Value(pinnedUpstreamKey{}).(*upstream); ok {
+		return u.client(ctx)
+	}
+	ranked := p.ranked()
+	if len(ranked) == 0 {
+		return nil, ErrNoUpstream
+	}
+	return ranked[0].client(ctx)
+}
+
+// CallContext performs a JSON-RPC call on the node pinned by ctx. If ctx
+// is not pinned, the call fails over to the next node on connection errors.
+func (p *upstreamPool) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	start := time.Now()
+	err := p.do(ctx, method, func(ctx context.Context, c *rpc.Client) error {
+		return c.CallContext(ctx, result, method, args...)
+	})
+	observeCall(method, start, err)
+	return err
+}
+
+// BatchCallContext sends a batch of JSON-RPC calls like CallContext.
+func (p *upstreamPool) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	method := "batch"
+	if len(b) > 0 {
+		method = b[0].Method
+	}
+	start := time.Now()
+	err := p.do(ctx, method, func(ctx context.Context, c *rpc.Client) error {
+		return c.BatchCallContext(ctx, b)
+	})
+	observeBatch(b, start, err)
+	return err
+}
+
+func (p *upstreamPool) do(
+	ctx context.Context,
+	method string,
+	call func(ctx context.Context, c *rpc.Client) error,
+) error {
+	upstreams := p.ranked()
+	if u, ok := ctx.Value(pinnedUpstreamKey{}).(*upstream); ok {
+		upstreams = []*upstream{u}
+	}
+	if len(upstreams) == 0 {
+		return ErrNoUpstream
+	}
+
+	logger := common.Logger(ctx)
+	var err error
+	for _, u := range upstreams {
+		start := time.Now()
+		err = u.call(ctx, call)
+		logger.Debug("tomo call", "method", method, "url", u.url, "duration", time.Since(start), "err", err)
+		if err == nil || !isConnectionError(ctx, err) {
+			return err
+		}
+		logger.Warn("tomo unreachable", "method", method, "url", u.url, "err", err)
+		p.markUnreachable(u, err)
+	}
+	return err
+}
+
+// call runs a call on the node within the request timeout, which only
+// the HTTP transport enforces by itself.
+func (u *upstream) call(ctx context.Context, call func(ctx context.Context, c *rpc.Client) error) error {
+	c, err := u.client(ctx)
+	if err != nil {
+		return err
+	}
+	if u.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.timeout)
+		defer cancel()
+	}
+	return call(ctx, c)
+}
+
+// Broadcast performs a JSON-RPC call on all the reachable nodes, it
+// succeeds if any of them succeeds. It is used to submit transactions.
+func (p *upstreamPool) Broadcast(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var firstErr error
+	succeeded := false
+	for _, u := range p.ranked() {
+		if health := p.healthOf(u); health.Checked && !health.Reachable {
+			continue
+		}
+		target := result
+		if succeeded {
+			// keep the result of the first successful call
+			target = nil
+		}
+		start := time.Now()
+		err := u.call(ctx, func(ctx context.Context, c *rpc.Client) error {
+			return c.CallContext(ctx, target, method, args...)
+		})
+		observeCall(method, start, err)
+		if err == nil {
+			succeeded = true
+			continue
+		}
+		if isConnectionError(ctx, err) {
+			p.markUnreachable(u, err)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if succeeded {
+			common.Logger(ctx).Warn("unable to broadcast to tomo", "method", method, "url", u.url, "err", err)
+		}
+	}
+
+	if succeeded {
+		return nil
+	}
+	if firstErr == nil {
+		firstErr = ErrNoUpstream
+	}
+	return firstErr
+}
+
+func (p *upstreamPool) healthOf(u *upstream) *UpstreamHealth {
+	p.RLock()
+	defer p.RUnlock()
+	return u.health
+}
+
+// markUnreachable excludes a node until its next health check succeeds.
+func (p *upstreamPool) markUnreachable(u *upstream, err error) {
+	p.Lock()
+	defer p.Unlock()
+	health := *u.health
+	health.Checked = true
+	health.Reachable = false
+	health.Error = err.Error()
+	u.health = &health
+}
+
+// isConnectionError reports whether err is caused by the connection to
+// the node rather than by the request, which the node answered.
+func isConnectionError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var rpcErr interface{ ErrorCode() int }
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	// the node answered with a result which could not be decoded
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	return !errors.As(err, &typeErr) && !errors.As(err, &syntaxErr)
+}
+
+// UpstreamHealth returns the result of the last health check of each tomo node.
+func (tc *Client) UpstreamHealth() []*UpstreamHealth {
+	return tc.c.Health()
+}
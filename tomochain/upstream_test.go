@@ -0,0 +1,212 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+)
+
+// fakeTomo is a JSON-RPC server answering calls with
+// the results recorded per method.
+type fakeTomo struct {
+	sync.Mutex
+	*httptest.Server
+	results map[string]func(params []json.RawMessage) (interface{}, error)
+}
+
+func newFakeTomo(t *testing.T) *fakeTomo {
+	f := &fakeTomo{results: map[string]func([]json.RawMessage) (interface{}, error){}}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+			return
+		}
+
+		f.Lock()
+		handler, ok := f.results[req.Method]
+		f.Unlock()
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if !ok {
+			response["error"] = map[string]interface{}{"code": -32601, "message": fmt.Sprintf("the method %s does not exist", req.Method)}
+		} else if result, err := handler(req.Params); err != nil {
+			response["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+		} else {
+			response["result"] = result
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	return f
+}
+
+// handle records the result of a method.
+func (f *fakeTomo) handle(method string, handler func(params []json.RawMessage) (interface{}, error)) {
+	f.Lock()
+	defer f.Unlock()
+	f.results[method] = handler
+}
+
+// chain makes the node serve a chain of the given chain ID whose block
+// hashes are derived from fork, up to head.
+func (f *fakeTomo) chain(chainID uint64, head uint64, fork string) {
+	f.handle(common.RPC_METHOD_GET_CHAIN_ID, func([]json.RawMessage) (interface{}, error) {
+		return fmt.Sprintf("0x%x", chainID), nil
+	})
+	f.handle(common.RPC_METHOD_SYNCING, func([]json.RawMessage) (interface{}, error) {
+		return false, nil
+	})
+	f.handle(common.RPC_METHOD_GET_BLOCK_BY_NUMBER, func(params []json.RawMessage) (interface{}, error) {
+		number := head
+		var arg string
+		if err := json.Unmarshal(params[0], &arg); err != nil {
+			return nil, err
+		}
+		if arg != "latest" {
+			n, ok := new(big.Int).SetString(arg[2:], 16)
+			if !ok {
+				return nil, fmt.Errorf("invalid block number %s", arg)
+			}
+			number = n.Uint64()
+		}
+		return map[string]interface{}{
+			"hash":      fmt.Sprintf("0x%062x%s", number, fork),
+			"number":    fmt.Sprintf("0x%x", number),
+			"timestamp": "0x5f5e1000",
+		}, nil
+	})
+}
+
+func newTestPool(t *testing.T, nodes ...*fakeTomo) *upstreamPool {
+	urls := make([]string, len(nodes))
+	for i, node := range nodes {
+		urls[i] = node.URL
+	}
+	p, err := newUpstreamPool(urls, 0, big.NewInt(88))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func rankedURLs(p *upstreamPool) []string {
+	var urls []string
+	for _, u := range p.ranked() {
+		urls = append(urls, u.url)
+	}
+	return urls
+}
+
+func TestRankedExcludesWrongChain(t *testing.T) {
+	mainnet, testnet := newFakeTomo(t), newFakeTomo(t)
+	defer mainnet.Close()
+	defer testnet.Close()
+	mainnet.chain(88, 100, "aa")
+	testnet.chain(89, 200, "bb")
+
+	p := newTestPool(t, testnet, mainnet)
+	defer p.Close()
+	if urls := rankedURLs(p); len(urls) != 1 || urls[0] != mainnet.URL {
+		t.Fatalf("ranked %v, want only %s", urls, mainnet.URL)
+	}
+
+	mainnet.Close()
+	p.checkAll()
+	if urls := rankedURLs(p); len(urls) != 1 || urls[0] != mainnet.URL {
+		t.Fatalf("ranked %v, want only the unreachable %s", urls, mainnet.URL)
+	}
+
+	testnetOnly := newTestPool(t, testnet)
+	defer testnetOnly.Close()
+	if urls := rankedURLs(testnetOnly); len(urls) != 0 {
+		t.Fatalf("ranked %v, want none", urls)
+	}
+	var result interface{}
+	if err := testnetOnly.CallContext(testnetOnly.pin(context.Background()), &result, common.RPC_METHOD_GET_CHAIN_ID); err != ErrNoUpstream {
+		t.Fatalf("call error %v, want %v", err, ErrNoUpstream)
+	}
+	if err := testnetOnly.Broadcast(context.Background(), &result, common.RPC_METHOD_SEND_SIGNED_TRANSACTION); err != ErrNoUpstream {
+		t.Fatalf("broadcast error %v, want %v", err, ErrNoUpstream)
+	}
+}
+
+func TestCheckAllMarksForks(t *testing.T) {
+	tests := []struct {
+		name   string
+		heads  []uint64
+		forks  []string
+		forked []bool
+	}{
+		{
+			name:   "minority is forked",
+			heads:  []uint64{100, 100, 100},
+			forks:  []string{"aa", "bb", "aa"},
+			forked: []bool{false, true, false},
+		},
+		{
+			name:   "lower node on another fork",
+			heads:  []uint64{120, 90, 110},
+			forks:  []string{"aa", "bb", "aa"},
+			forked: []bool{false, true, false},
+		},
+		{
+			name:   "tie won by the highest head",
+			heads:  []uint64{90, 100},
+			forks:  []string{"aa", "bb"},
+			forked: []bool{true, false},
+		},
+		{
+			name:   "same chain at different heights",
+			heads:  []uint64{90, 100},
+			forks:  []string{"aa", "aa"},
+			forked: []bool{false, false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes := make([]*fakeTomo, len(test.heads))
+			for i := range nodes {
+				nodes[i] = newFakeTomo(t)
+				defer nodes[i].Close()
+				nodes[i].chain(88, test.heads[i], test.forks[i])
+			}
+
+			p := newTestPool(t, nodes...)
+			defer p.Close()
+			health := p.Health()
+			ranked := map[string]bool{}
+			for _, url := range rankedURLs(p) {
+				ranked[url] = true
+			}
+			for i, node := range nodes {
+				if health[i].Forked != test.forked[i] {
+					t.Errorf("node %d forked %v, want %v", i, health[i].Forked, test.forked[i])
+				}
+				if ranked[node.URL] == test.forked[i] {
+					t.Errorf("node %d ranked %v, want %v", i, ranked[node.URL], !test.forked[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"fmt"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/tomochain/tomochain"
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	"github.com/tomochain/tomochain/accounts/abi/bind"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/consensus/posv"
+	"github.com/tomochain/tomochain/contracts/validator/contract"
+	tomochaintypes "github.com/tomochain/tomochain/core/types"
+	"github.com/tomochain/tomochain/ethclient"
+	"math/big"
+	"sort"
+)
+
+// Masternodes is the output of the call method "tomo_getMasternodes".
+type Masternodes struct {
+	BlockIdentifier      *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+	CheckpointIdentifier *RosettaTypes.BlockIdentifier `json:"checkpoint_identifier"`
+	Epoch                uint64                        `json:"epoch"`
+	Masternodes          []string                      `json:"masternodes"`
+}
+
+// Candidates is the output of the call method "tomo_getCandidates".
+type Candidates struct {
+	BlockIdentifier      *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+	CheckpointIdentifier *RosettaTypes.BlockIdentifier `json:"checkpoint_identifier"`
+	Epoch                uint64                        `json:"epoch"`
+	Candidates           []*Candidate                  `json:"candidates"`
+}
+
+// Candidate is a masternode candidate registered in the
+// TomoValidator contract.
+type Candidate struct {
+	Address  string `json:"address"`
+	Owner    string `json:"owner"`
+	Capacity string `json:"capacity"`
+
+	// Status is MASTERNODE for the masternodes of the checkpoint block,
+	// SLASHED for the other candidates listed in the penalties of the
+	// checkpoint block or of the LimitPenaltyEpoch checkpoints before it,
+	// and PROPOSED otherwise.
+	Status string `json:"status"`
+}
+
+// CandidateVoters is the output of the call method "tomo_getCandidateVoters".
+type CandidateVoters struct {
+	BlockIdentifier *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+	Candidate       string                        `json:"candidate"`
+	Owner           string                        `json:"owner"`
+	Capacity        string                        `json:"capacity"`
+	Voters          []*Voter                      `json:"voters"`
+}
+
+// Voter is a voter of a masternode candidate and its stake.
+type Voter struct {
+	Address  string `json:"address"`
+	Capacity string `json:"capacity"`
+}
+
+func blockIdentifierOf(head *rpcPosvHeader) *RosettaTypes.BlockIdentifier {
+	return &RosettaTypes.BlockIdentifier{
+		Hash:  head.Hash.Hex(),
+		Index: head.Number.ToInt().Int64(),
+	}
+}
+
+// getCheckpointHeader returns the header of the last checkpoint block
+// at or before the given block number.
+func (tc *Client) getCheckpointHeader(ctx context.Context, number uint64) (*rpcPosvHeader, error) {
+	checkpoint := number - number%tc.rules.Epoch()
+	return tc.getPosvHeader(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(new(big.Int).SetUint64(checkpoint)))
+}
+
+// masternodesFromCheckpoint returns the masternodes stored in the
+// extra-data of a checkpoint header.
+func masternodesFromCheckpoint(checkpoint *rpcPosvHeader) []tomochaincommon.Address {
+	if len(checkpoint.Extra) < common.ExtraVanity+common.ExtraSeal {
+		return []tomochaincommon.Address{}
+	}
+	return posv.GetMasternodesFromCheckpointHeader(&tomochaintypes.Header{Extra: checkpoint.Extra})
+}
+
+// blockContractCaller executes contract calls against the
+// state of a fixed block.
+type blockContractCaller struct {
+	*ethclient.Client
+	number *big.Int
+}
+
+// CodeAt implements bind.ContractCaller.
+func (b *blockContractCaller) CodeAt(ctx context.Context, account tomochaincommon.Address, _ *big.Int) ([]byte, error) {
+	return b.Client.CodeAt(ctx, account, b.number)
+}
+
+// CallContract implements bind.ContractCaller.
+func (b *blockContractCaller) CallContract(ctx context.Context, msg tomochain.CallMsg, _ *big.Int) ([]byte, error) {
+	return b.Client.CallContract(ctx, msg, b.number)
+}
+
+// validatorCaller returns a binding to the TomoValidator contract
+// at the given block.
+func (tc *Client) validatorCaller(ctx context.Context, number *big.Int) (*contract.TomoValidatorCaller, error) {
+	c, err := tc.c.rpcClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return contract.NewTomoValidatorCaller(
+		tomochaincommon.HexToAddress(tomochaincommon.MasternodeVotingSMC),
+		&blockContractCaller{Client: ethclient.NewClient(c), number: number},
+	)
+}
+
+// masternodes returns the masternode set of a block
+func (tc *Client) masternodes(ctx context.Context, input *GetMasternodesInput) (*Masternodes, error) {
+	head, err := tc.getPosvHeaderByIndexOrHash(ctx, input.Index, input.Hash)
+	if err != nil {
+		return nil, err
+	}
+	number := head.Number.ToInt().Uint64()
+	checkpoint, err := tc.getCheckpointHeader(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get checkpoint of block %d", err, number)
+	}
+
+	result := &Masternodes{
+		BlockIdentifier:      blockIdentifierOf(head),
+		CheckpointIdentifier: blockIdentifierOf(checkpoint),
+		Epoch:                number / tc.rules.Epoch(),
+		Masternodes:          []string{},
+	}
+	for _, masternode := range masternodesFromCheckpoint(checkpoint) {
+		result.Masternodes = append(result.Masternodes, masternode.Hex())
+	}
+	return result, nil
+}
+
+// candidates returns all masternode candidates of a block with their status
+func (tc *Client) candidates(ctx context.Context, input *GetCandidatesInput) (*Candidates, error) {
+	head, err := tc.getPosvHeaderByIndexOrHash(ctx, input.Index, input.Hash)
+	if err != nil {
+		return nil, err
+	}
+	number := head.Number.ToInt()
+	checkpoint, err := tc.getCheckpointHeader(ctx, number.Uint64())
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get checkpoint of block %d", err, number)
+	}
+
+	validator, err := tc.validatorCaller(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	addresses, err := validator.GetCandidates(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get candidates", err)
+	}
+
+	candidates := []*Candidate{}
+	capacities := map[string]*big.Int{}
+	for _, address := range addresses {
+		if address == (tomochaincommon.Address{}) {
+			continue
+		}
+		capacity, err := validator.GetCandidateCap(opts, address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get capacity of candidate %s", err, address.Hex())
+		}
+		owner, err := validator.GetCandidateOwner(opts, address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get owner of candidate %s", err, address.Hex())
+		}
+		capacities[address.Hex()] = capacity
+		candidates = append(candidates, &Candidate{
+			Address:  address.Hex(),
+			Owner:    owner.Hex(),
+			Capacity: capacity.String(),
+			Status:   common.CANDIDATE_STATUS_PROPOSED,
+		})
+	}
+	// sort candidates by capacity descending, same as the masternode election
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return capacities[candidates[i].Address].Cmp(capacities[candidates[j].Address]) > 0
+	})
+
+	masternodes := map[string]bool{}
+	for _, masternode := range masternodesFromCheckpoint(checkpoint) {
+		masternodes[masternode.Hex()] = true
+	}
+	penalties, err := tc.recentPenalties(ctx, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		switch {
+		case masternodes[candidate.Address]:
+			candidate.Status = common.CANDIDATE_STATUS_MASTERNODE
+		case penalties[candidate.Address]:
+			candidate.Status = common.CANDIDATE_STATUS_SLASHED
+		}
+	}
+
+	return &Candidates{
+		BlockIdentifier:      blockIdentifierOf(head),
+		CheckpointIdentifier: blockIdentifierOf(checkpoint),
+		Epoch:                number.Uint64() / tc.rules.Epoch(),
+		Candidates:           candidates,
+	}, nil
+}
+
+// recentPenalties returns the masternodes penalized at the checkpoint
+// and at the last LimitPenaltyEpoch checkpoints before it.
+func (tc *Client) recentPenalties(ctx context.Context, checkpoint *rpcPosvHeader) (map[string]bool, error) {
+	penalties := append([]byte{}, checkpoint.Penalties...)
+	number := checkpoint.Number.ToInt().Uint64()
+	for i := uint64(1); i <= tomochaincommon.LimitPenaltyEpoch; i++ {
+		if number < tc.rules.Epoch()*i {
+			break
+		}
+		previous, err := tc.getPosvHeader(ctx, common.RPC_METHOD_GET_BLOCK_BY_NUMBER, toBlockNumArg(new(big.Int).SetUint64(number-tc.rules.Epoch()*i)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get penalties of checkpoint %d", err, number-tc.rules.Epoch()*i)
+		}
+		penalties = append(penalties, previous.Penalties...)
+	}
+
+	result := map[string]bool{}
+	for _, penalty := range tomochaincommon.ExtractAddressFromBytes(penalties) {
+		result[penalty.Hex()] = true
+	}
+	return result, nil
+}
+
+// candidateVoters returns the voters of a masternode candidate and their stakes
+func (tc *Client) candidateVoters(ctx context.Context, input *GetCandidateVotersInput) (*CandidateVoters, error) {
+	candidate, ok := ChecksumAddress(input.Candidate)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid candidate %s", ErrCallParametersInvalid, input.Candidate)
+	}
+	head, err := tc.getPosvHeaderByIndexOrHash(ctx, input.Index, input.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := tc.validatorCaller(ctx, head.Number.ToInt())
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	candidateAddr := tomochaincommon.HexToAddress(candidate)
+	isCandidate, err := validator.IsCandidate(opts, candidateAddr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to check candidate %s", err, candidate)
+	}
+	if !isCandidate {
+		return nil, fmt.Errorf("%w: %s is not a candidate", ErrCallParametersInvalid, candidate)
+	}
+	owner, err := validator.GetCandidateOwner(opts, candidateAddr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get owner of candidate %s", err, candidate)
+	}
+	capacity, err := validator.GetCandidateCap(opts, candidateAddr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get capacity of candidate %s", err, candidate)
+	}
+	addresses, err := validator.GetVoters(opts, candidateAddr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get voters of candidate %s", err, candidate)
+	}
+
+	// a voter is listed once per vote, report it once
+	voters := []*Voter{}
+	seen := map[tomochaincommon.Address]bool{}
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		voterCap, err := validator.GetVoterCap(opts, candidateAddr, address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get stake of voter %s", err, address.Hex())
+		}
+		if voterCap.Sign() <= 0 {
+			continue
+		}
+		voters = append(voters, &Voter{
+			Address:  address.Hex(),
+			Capacity: voterCap.String(),
+		})
+	}
+
+	return &CandidateVoters{
+		BlockIdentifier: blockIdentifierOf(head),
+		Candidate:       candidate,
+		Owner:           owner.Hex(),
+		Capacity:        capacity.String(),
+		Voters:          voters,
+	}, nil
+}
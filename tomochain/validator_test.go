@@ -0,0 +1,76 @@
+// Copyright (c) 2020 TomoChain
+
+package tomochain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/tomochain/tomochain-rosetta-gateway/common"
+	tomochaincommon "github.com/tomochain/tomochain/common"
+	"github.com/tomochain/tomochain/common/hexutil"
+)
+
+func TestRecentPenalties(t *testing.T) {
+	// the checkpoint of epoch n penalizes the address n,
+	// the genesis block penalizes none
+	node := newFakeTomo(t)
+	defer node.Close()
+	node.chain(88, 9000, "aa")
+	node.handle(common.RPC_METHOD_GET_BLOCK_BY_NUMBER, func(params []json.RawMessage) (interface{}, error) {
+		var arg string
+		if err := json.Unmarshal(params[0], &arg); err != nil {
+			return nil, err
+		}
+		number := uint64(9000)
+		if arg != "latest" {
+			n, ok := new(big.Int).SetString(arg[2:], 16)
+			if !ok {
+				return nil, fmt.Errorf("invalid block number %s", arg)
+			}
+			number = n.Uint64()
+		}
+		penalties := hexutil.Bytes{}
+		if number > 0 {
+			penalties = tomochaincommon.BigToAddress(new(big.Int).SetUint64(number / 900)).Bytes()
+		}
+		return map[string]interface{}{
+			"hash":      fmt.Sprintf("0x%064x", number),
+			"number":    fmt.Sprintf("0x%x", number),
+			"timestamp": "0x5f5e1000",
+			"penalties": penalties,
+		}, nil
+	})
+
+	client := newTestClient(t, node)
+	defer client.Close()
+	tests := []struct {
+		checkpoint uint64
+		penalized  []int64
+	}{
+		{900, []int64{1}},
+		{2700, []int64{1, 2, 3}},
+		{9000, []int64{6, 7, 8, 9, 10}},
+	}
+	for _, test := range tests {
+		checkpoint, err := client.getCheckpointHeader(context.Background(), test.checkpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		penalties, err := client.recentPenalties(context.Background(), checkpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(penalties) != len(test.penalized) {
+			t.Errorf("checkpoint %d penalizes %v, want %v", test.checkpoint, penalties, test.penalized)
+		}
+		for _, epoch := range test.penalized {
+			if !penalties[tomochaincommon.BigToAddress(big.NewInt(epoch)).Hex()] {
+				t.Errorf("checkpoint %d does not penalize the address of epoch %d", test.checkpoint, epoch)
+			}
+		}
+	}
+}